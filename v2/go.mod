@@ -1,3 +1,14 @@
 module github.com/booscaaa/go-paginate/v2
 
 go 1.21.3
+
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/Masterminds/squirrel v1.5.4
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0 // indirect
+	github.com/lann/ps v0.0.0-20150810152359-62de8c46ede0 // indirect
+)
@@ -0,0 +1,193 @@
+package paginate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client builds request URLs with the query params read by WithQuery, for Go
+// services calling paginated endpoints.
+type Client struct {
+	baseURL    string
+	values     url.Values
+	dateLayout string
+}
+
+// PageSizeOption is a page size preset shared by clients and servers.
+type PageSizeOption int
+
+// Page size presets.
+const (
+	PageSizeSmall  PageSizeOption = 10
+	PageSizeMedium PageSizeOption = 25
+	PageSizeLarge  PageSizeOption = 100
+)
+
+// NewClient returns a Client building URLs for the endpoint at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{baseURL: baseURL, values: url.Values{}, dateLayout: time.RFC3339}
+}
+
+// Page sets the page param. Pages below 1 are ignored.
+func (client *Client) Page(page int) *Client {
+	if page >= 1 {
+		client.values.Set("page", strconv.Itoa(page))
+	}
+	return client
+}
+
+// Limit sets the limit param. Limits below 1 are ignored.
+func (client *Client) Limit(limit int) *Client {
+	if limit >= 1 {
+		client.values.Set("limit", strconv.Itoa(limit))
+	}
+	return client
+}
+
+// PageSize sets the limit param from a preset.
+func (client *Client) PageSize(size PageSizeOption) *Client {
+	return client.Limit(int(size))
+}
+
+// Search sets the search term and the fields to search.
+func (client *Client) Search(term string, fields ...string) *Client {
+	client.values.Set("search", term)
+	if len(fields) > 0 {
+		client.values.Set("search_fields", strings.Join(fields, ","))
+	}
+	return client
+}
+
+// Sort sets the sort param from fields, "-" prefixed for DESC.
+func (client *Client) Sort(fields ...string) *Client {
+	client.values.Set("sort", strings.Join(fields, ","))
+	return client
+}
+
+// Filter adds an op[field] filter param, joining multiple values with commas.
+func (client *Client) Filter(operator Operator, field string, values ...interface{}) *Client {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = fmt.Sprint(value)
+	}
+	client.values.Add(string(operator)+"["+field+"]", strings.Join(items, ","))
+	return client
+}
+
+// Between adds a between[field] filter param. A nil bound is sent empty, which
+// Validate reports as an incomplete range.
+func (client *Client) Between(field string, low, high interface{}) *Client {
+	return client.Filter(OpBetween, field, clientValue(low), clientValue(high))
+}
+
+// DateLayout sets the layout of the Date* filter values, time.RFC3339 by
+// default. Validate rejects layouts with a comma, which would split the values
+// of the comma-separated between ranges.
+func (client *Client) DateLayout(layout string) *Client {
+	client.dateLayout = layout
+	return client
+}
+
+// DateBetween adds a between[field] filter param with the formatted times. A
+// zero time is an open bound: a zero start adds a lte[field] param, a zero end
+// a gte[field] param, and two zero times no param at all.
+func (client *Client) DateBetween(field string, start, end time.Time) *Client {
+	switch {
+	case start.IsZero() && end.IsZero():
+		return client
+	case start.IsZero():
+		return client.DateLte(field, end)
+	case end.IsZero():
+		return client.DateGte(field, start)
+	}
+	return client.Between(field, client.formatDate(start), client.formatDate(end))
+}
+
+// DateGte adds a gte[field] filter param with the formatted time.
+func (client *Client) DateGte(field string, t time.Time) *Client {
+	return client.Filter(OpGte, field, client.formatDate(t))
+}
+
+// DateLte adds a lte[field] filter param with the formatted time.
+func (client *Client) DateLte(field string, t time.Time) *Client {
+	return client.Filter(OpLte, field, client.formatDate(t))
+}
+
+// formatDate formats the time with the date layout, leaving a zero time nil.
+func (client *Client) formatDate(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(client.dateLayout)
+}
+
+// Validate checks the params for incomplete between ranges, filters without a
+// field name, conflicting params and a date layout with a comma.
+func (client *Client) Validate() error {
+	if strings.Contains(client.dateLayout, ",") {
+		return fmt.Errorf("invalid date layout %q: commas split the between ranges", client.dateLayout)
+	}
+
+	keys := make([]string, 0, len(client.values))
+	for key := range client.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.Contains(key, "[") {
+			continue
+		}
+		operator, field, ok := parseFilterKey(key)
+		if !ok {
+			return fmt.Errorf("invalid filter %q: empty field name", key)
+		}
+		for _, value := range client.values[key] {
+			if operator != OpBetween && operator != OpNotBetween {
+				continue
+			}
+			bounds := strings.Split(value, ",")
+			if len(bounds) != 2 || bounds[0] == "" || bounds[1] == "" {
+				return fmt.Errorf("incomplete %s range %q for field %q", operator, value, field)
+			}
+		}
+		if operator == OpEq && len(client.values[key]) > 1 {
+			return fmt.Errorf("conflicting filters: field %q can't equal %s", field, strings.Join(client.values[key], " and "))
+		}
+	}
+
+	if client.values.Has("search_fields") && client.values.Get("search") == "" {
+		return errors.New("conflicting params: search_fields without a search term")
+	}
+	return nil
+}
+
+// clientValue formats a filter value, leaving nil empty.
+func clientValue(value interface{}) interface{} {
+	if value == nil {
+		return ""
+	}
+	return value
+}
+
+// BuildURL validates the params and returns the base URL with them.
+func (client *Client) BuildURL() (string, error) {
+	if err := client.Validate(); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(client.baseURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	for key, values := range client.values {
+		query[key] = append(query[key], values...)
+	}
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
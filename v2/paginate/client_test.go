@@ -0,0 +1,146 @@
+package paginate
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestClientPageSize tests that each page size preset sets the limit param.
+func TestClientPageSize(t *testing.T) {
+	tests := []struct {
+		size          PageSizeOption
+		expectedLimit string
+	}{
+		{PageSizeSmall, "10"},
+		{PageSizeMedium, "25"},
+		{PageSizeLarge, "100"},
+	}
+
+	for _, tt := range tests {
+		rawURL, err := NewClient("https://api.example.com/users?tenant=1").PageSize(tt.size).BuildURL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if limit := u.Query().Get("limit"); limit != tt.expectedLimit {
+			t.Errorf("Expected limit %s, got: %s", tt.expectedLimit, limit)
+		}
+		if tenant := u.Query().Get("tenant"); tenant != "1" {
+			t.Errorf("Expected the base URL params to be kept, got: %s", rawURL)
+		}
+	}
+}
+
+// TestClientBuildURL tests that the client URL params are read back by WithQuery.
+func TestClientBuildURL(t *testing.T) {
+	rawURL, err := NewClient("https://api.example.com/posts").
+		Page(2).
+		PageSize(PageSizeMedium).
+		Search("go", "title").
+		Sort("-published", "title").
+		Filter(OpIn, "id", 1, 2).
+		BuildURL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedURL := "https://api.example.com/posts?in%5Bid%5D=1%2C2&limit=25&page=2&search=go&search_fields=title&sort=-published%2Ctitle"
+	if rawURL != expectedURL {
+		t.Errorf("Expected URL:\n%s\nGot:\n%s", expectedURL, rawURL)
+	}
+
+	u, _ := url.Parse(rawURL)
+	p, err := NewPaginator(WithTable("posts"), WithStruct(Post{}), WithQuery(u.Query()))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE (posts.title::TEXT ILIKE $1) AND posts.id IN ($2, $3) ORDER BY posts.published DESC, posts.title ASC LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestClientValidate tests the validation of client params.
+func TestClientValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		client      *Client
+		expectError bool
+	}{
+		{"valid", NewClient("/users").Between("age", 18, 30).Filter(OpEq, "name", "john"), false},
+		{"incomplete between", NewClient("/users").Between("age", 18, nil), true},
+		{"empty field", NewClient("/users").Filter(OpEq, "", "john"), true},
+		{"conflicting equality", NewClient("/users").Filter(OpEq, "name", "john").Filter(OpEq, "name", "jane"), true},
+		{"search fields without search", NewClient("/users").Search("", "name"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.client.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if _, buildErr := tt.client.BuildURL(); (buildErr != nil) != tt.expectError {
+				t.Errorf("Expected BuildURL to match Validate, got: %v", buildErr)
+			}
+		})
+	}
+}
+
+// TestClientDates tests formatting date filters in the URL.
+func TestClientDates(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.FixedZone("BRT", -3*60*60))
+
+	rawURL, err := NewClient("/orders").
+		DateBetween("created_at", start, end).
+		DateGte("paid_at", start).
+		BuildURL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	u, _ := url.Parse(rawURL)
+	if got := u.Query().Get("between[created_at]"); got != "2024-01-01T00:00:00Z,2024-01-31T23:59:59-03:00" {
+		t.Errorf("Unexpected between value: %s", got)
+	}
+	if got := u.Query().Get("gte[paid_at]"); got != "2024-01-01T00:00:00Z" {
+		t.Errorf("Unexpected gte value: %s", got)
+	}
+
+	rawURL, err = NewClient("/orders").DateLayout("2006-01-02").DateLte("created_at", end).BuildURL()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	u, _ = url.Parse(rawURL)
+	if got := u.Query().Get("lte[created_at]"); got != "2024-01-31" {
+		t.Errorf("Unexpected lte value: %s", got)
+	}
+
+	// A zero time is an open bound.
+	client := NewClient("/orders").
+		DateBetween("created_at", start, time.Time{}).
+		DateBetween("paid_at", time.Time{}, end).
+		DateBetween("shipped_at", time.Time{}, time.Time{})
+	if err := client.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rawURL, _ = client.BuildURL()
+	u, _ = url.Parse(rawURL)
+	expected := url.Values{"gte[created_at]": {"2024-01-01T00:00:00Z"}, "lte[paid_at]": {"2024-01-31T23:59:59-03:00"}}
+	if !reflect.DeepEqual(u.Query(), expected) {
+		t.Errorf("Expected open bounds %v, got: %v", expected, u.Query())
+	}
+
+	if err := NewClient("/orders").DateLayout("Jan 2, 2006").DateGte("created_at", start).Validate(); err == nil {
+		t.Error("Expected error for a date layout with a comma")
+	}
+}
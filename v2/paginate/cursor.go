@@ -0,0 +1,216 @@
+package paginate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// EncodeCursor encodes the keyset values into an opaque, URL-safe cursor.
+func EncodeCursor(values map[string]interface{}) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("encoding cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a cursor produced by EncodeCursor. Numbers are decoded
+// as json.Number to keep their precision.
+func DecodeCursor(cursor string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("decoding cursor: %w", err)
+	}
+
+	var values map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("decoding cursor: %w", err)
+	}
+	return values, nil
+}
+
+// BuildNextCursor encodes the sort column values of the last returned row into
+// the cursor of the next page.
+func BuildNextCursor(lastRow map[string]interface{}, sortColumns []string) (string, error) {
+	if len(sortColumns) == 0 {
+		return "", fmt.Errorf("at least one sort column is required to build a cursor")
+	}
+
+	values := make(map[string]interface{}, len(sortColumns))
+	for _, column := range sortColumns {
+		value, ok := lastRow[column]
+		if !ok {
+			return "", fmt.Errorf("sort column %q is missing from the last row", column)
+		}
+		values[column] = value
+	}
+	return EncodeCursor(values)
+}
+
+// Cursor is the keyset position of a page: the rows after Values in the
+// Directions, ASC or DESC, of Fields. Nil Values is the first page.
+type Cursor struct {
+	Fields     []string
+	Values     []interface{}
+	Directions []string
+}
+
+// WithCursor paginates by keyset instead of OFFSET: it filters the rows after
+// lastValue in field, sorts by field in the direction, ASC or DESC, before
+// the other sort columns, and drops the OFFSET. A nil lastValue requests the
+// first page. The field should be unique, e.g. the id, as rows tied with
+// lastValue are skipped; the other sort columns only order the rows within
+// the page. The lastValue of the next page is the field of the last row, see
+// BuildNextCursor.
+func WithCursor(field string, lastValue interface{}, direction string) Option {
+	return func(params *QueryParams) {
+		var values []interface{}
+		if lastValue != nil {
+			values = []interface{}{lastValue}
+		}
+		params.Cursor = &Cursor{Fields: []string{field}, Values: values, Directions: []string{direction}}
+	}
+}
+
+// WithCompositeCursor is WithCursor over a key of several fields, e.g.
+// created_at and id, so a non-unique field is made unique by the next one. A
+// key in a single direction is compared as a row value, `(a, b) > (?, ?)`,
+// and mixed directions as `(a > ? OR (a = ? AND b < ?))`. Key fields are
+// assumed NOT NULL unless a value is nil or NullsOrdering is set, which
+// switch to comparisons placing NULLs like the ORDER BY. Nil values request
+// the first page; otherwise there must be one value and one direction per
+// field.
+func WithCompositeCursor(fields []string, values []interface{}, directions []string) Option {
+	return func(params *QueryParams) {
+		params.Cursor = &Cursor{Fields: fields, Values: values, Directions: directions}
+	}
+}
+
+// validate checks the lengths and the directions.
+func (cursor Cursor) validate() error {
+	if len(cursor.Fields) == 0 {
+		return errors.New("cursor requires at least one field")
+	}
+	if len(cursor.Directions) != len(cursor.Fields) || (cursor.Values != nil && len(cursor.Values) != len(cursor.Fields)) {
+		return fmt.Errorf("cursor has %d fields, %d values and %d directions", len(cursor.Fields), len(cursor.Values), len(cursor.Directions))
+	}
+	for i, direction := range cursor.Directions {
+		switch strings.ToUpper(direction) {
+		case "ASC", "DESC":
+			continue
+		}
+		return fmt.Errorf("invalid cursor direction %q for field %q", direction, cursor.Fields[i])
+	}
+	return nil
+}
+
+// cursorPredicate returns the keyset condition of the cursor and its
+// arguments, or "" without a cursor or on the first page.
+func (params *QueryParams) cursorPredicate() (string, []interface{}) {
+	cursor := params.Cursor
+	if cursor == nil || cursor.Values == nil {
+		return "", nil
+	}
+	columns := make([]string, len(cursor.Fields))
+	for i, field := range cursor.Fields {
+		if columns[i] = params.guardedColumnName(field, "sort"); columns[i] == "" {
+			return "", nil
+		}
+	}
+
+	values := cursor.Values
+	if params.redacting {
+		values = slices.Clone(values)
+		for i, field := range cursor.Fields {
+			if values[i] != nil {
+				values[i] = params.redactedValues(field, values[i:i+1])[0]
+			}
+		}
+	}
+
+	nullable := params.NullsOrdering != NullsDefault || slices.Contains(values, nil)
+	sameDirection := !slices.ContainsFunc(cursor.Directions, func(direction string) bool {
+		return !strings.EqualFold(direction, cursor.Directions[0])
+	})
+	operator := func(i int) string {
+		if strings.EqualFold(cursor.Directions[i], "DESC") {
+			return "<"
+		}
+		return ">"
+	}
+
+	if len(columns) == 1 && !nullable {
+		return columns[0] + " " + operator(0) + " ?", values
+	}
+	if sameDirection && !nullable && params.Dialect.rowValues() {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+		return "(" + strings.Join(columns, ", ") + ") " + operator(0) + " (" + placeholders + ")", values
+	}
+
+	// Decomposed comparison: the rows after the value of one column, with the
+	// previous columns equal to theirs.
+	var disjuncts []string
+	var args []interface{}
+	for i := range columns {
+		after, afterArgs := params.cursorAfter(columns[i], values[i], cursor.Directions[i], operator(i), nullable)
+		if after == "" {
+			continue
+		}
+		conditions := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				conditions = append(conditions, columns[j]+" IS NULL")
+				continue
+			}
+			conditions = append(conditions, columns[j]+" = ?")
+			args = append(args, values[j])
+		}
+		conditions = append(conditions, after)
+		args = append(args, afterArgs...)
+		if len(conditions) == 1 {
+			disjuncts = append(disjuncts, after)
+			continue
+		}
+		disjuncts = append(disjuncts, "("+strings.Join(conditions, " AND ")+")")
+	}
+	if len(disjuncts) == 0 {
+		return "1 = 0", nil
+	}
+	if len(disjuncts) == 1 {
+		return disjuncts[0], args
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", args
+}
+
+// cursorAfter returns the condition of the rows after the value in the column
+// and its arguments, or "" when no row is. With nullable, NULLs are placed
+// like the ORDER BY: after NULL come the other values when NULLs are first,
+// and after a value come the NULLs when they are last.
+func (params *QueryParams) cursorAfter(columnName string, value interface{}, direction, operator string, nullable bool) (string, []interface{}) {
+	if !nullable {
+		return columnName + " " + operator + " ?", []interface{}{value}
+	}
+	nullsAfter := params.Dialect.nullsLast() != strings.EqualFold(direction, "DESC")
+	switch params.NullsOrdering {
+	case NullsFirst:
+		nullsAfter = false
+	case NullsLast:
+		nullsAfter = true
+	}
+	switch {
+	case value == nil && nullsAfter:
+		return "", nil
+	case value == nil:
+		return columnName + " IS NOT NULL", nil
+	case nullsAfter:
+		return "(" + columnName + " " + operator + " ? OR " + columnName + " IS NULL)", []interface{}{value}
+	}
+	return columnName + " " + operator + " ?", []interface{}{value}
+}
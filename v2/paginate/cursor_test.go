@@ -0,0 +1,196 @@
+package paginate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestBuildNextCursor tests building a cursor from the last row and decoding it back.
+func TestBuildNextCursor(t *testing.T) {
+	lastRow := map[string]interface{}{
+		"id":         42,
+		"name":       "john",
+		"created_at": "2024-01-02T03:04:05Z",
+	}
+
+	cursor, err := BuildNextCursor(lastRow, []string{"created_at", "id"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.ContainsAny(cursor, "+/=") {
+		t.Errorf("Expected a URL-safe cursor, got: %s", cursor)
+	}
+
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]interface{}{
+		"id":         json.Number("42"),
+		"created_at": "2024-01-02T03:04:05Z",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected values: %v\nGot: %v", expected, values)
+	}
+
+	// The same row always produces the same cursor.
+	again, _ := BuildNextCursor(lastRow, []string{"id", "created_at"})
+	if again != cursor {
+		t.Errorf("Expected a deterministic cursor, got: %s and %s", cursor, again)
+	}
+}
+
+// TestBuildNextCursorErrors tests invalid cursor inputs.
+func TestBuildNextCursorErrors(t *testing.T) {
+	_, err := BuildNextCursor(map[string]interface{}{"id": 1}, []string{"id", "created_at"})
+	if err == nil || !strings.Contains(err.Error(), `sort column "created_at" is missing`) {
+		t.Errorf("Expected missing column error, got: %v", err)
+	}
+
+	_, err = BuildNextCursor(map[string]interface{}{"id": 1}, nil)
+	if err == nil {
+		t.Errorf("Expected error without sort columns")
+	}
+
+	_, err = DecodeCursor("not a cursor!")
+	if err == nil || !strings.Contains(err.Error(), "decoding cursor") {
+		t.Errorf("Expected decoding error, got: %v", err)
+	}
+}
+
+// TestWithCursor tests keyset pagination after the cursor value in both directions.
+func TestWithCursor(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "ascending",
+			options:       []Option{WithCursor("id", 42, "asc"), WithFilter("name", OpEq, "john"), WithPage(3)},
+			expectedQuery: "SELECT * FROM users WHERE users.name = $1 AND users.id > $2 ORDER BY users.id ASC LIMIT $3",
+			expectedArgs:  []interface{}{"john", 42, 10},
+		},
+		{
+			name:          "descending with tie-breaker",
+			options:       []Option{WithCursor("age", 30, "DESC"), WithSort([]string{"id", "age"}, []string{"false", "false"})},
+			expectedQuery: "SELECT * FROM users WHERE users.age < $1 ORDER BY users.age DESC, users.id ASC LIMIT $2",
+			expectedArgs:  []interface{}{30, 10},
+		},
+		{
+			name:          "first page",
+			options:       []Option{WithCursor("id", nil, "ASC")},
+			expectedQuery: "SELECT * FROM users ORDER BY users.id ASC LIMIT $1",
+			expectedArgs:  []interface{}{10},
+		},
+		{
+			name:          "sql server",
+			options:       []Option{WithDialect(DialectSQLServer), WithCursor("id", 42, "ASC")},
+			expectedQuery: "SELECT * FROM users WHERE users.id > @p1 ORDER BY users.id ASC OFFSET 0 ROWS FETCH NEXT @p2 ROWS ONLY",
+			expectedArgs:  []interface{}{42, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := mustGenerateSQL(t, append([]Option{WithTable("users"), WithStruct(User{})}, tt.options...))
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", tt.expectedArgs, args)
+			}
+		})
+	}
+
+	for _, option := range []Option{WithCursor("id", 1, "up"), WithCursor("unknown", 1, "ASC")} {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), option); err == nil {
+			t.Error("Expected error for an invalid cursor")
+		}
+	}
+}
+
+// TestWithCompositeCursor tests keyset pagination over a created_at and id key.
+func TestWithCompositeCursor(t *testing.T) {
+	type Event struct {
+		ID        int    `json:"id" paginate:"events.id"`
+		CreatedAt string `json:"created_at" paginate:"events.created_at"`
+	}
+	fields := []string{"created_at", "id"}
+	createdAt := "2024-01-02T03:04:05Z"
+
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "ascending",
+			options:       []Option{WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"ASC", "ASC"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at, events.id) > ($1, $2) ORDER BY events.created_at ASC, events.id ASC LIMIT $3",
+			expectedArgs:  []interface{}{createdAt, 7, 10},
+		},
+		{
+			name:          "descending",
+			options:       []Option{WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"desc", "desc"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at, events.id) < ($1, $2) ORDER BY events.created_at DESC, events.id DESC LIMIT $3",
+			expectedArgs:  []interface{}{createdAt, 7, 10},
+		},
+		{
+			name:          "mixed directions",
+			options:       []Option{WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"DESC", "ASC"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at < $1 OR (events.created_at = $2 AND events.id > $3)) ORDER BY events.created_at DESC, events.id ASC LIMIT $4",
+			expectedArgs:  []interface{}{createdAt, createdAt, 7, 10},
+		},
+		{
+			name:          "without row values",
+			options:       []Option{WithDialect(DialectSQLServer), WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"ASC", "ASC"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at > @p1 OR (events.created_at = @p2 AND events.id > @p3)) ORDER BY events.created_at ASC, events.id ASC OFFSET 0 ROWS FETCH NEXT @p4 ROWS ONLY",
+			expectedArgs:  []interface{}{createdAt, createdAt, 7, 10},
+		},
+		{
+			name:          "null value with nulls last",
+			options:       []Option{WithCompositeCursor(fields, []interface{}{nil, 7}, []string{"ASC", "ASC"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at IS NULL AND (events.id > $1 OR events.id IS NULL)) ORDER BY events.created_at ASC, events.id ASC LIMIT $2",
+			expectedArgs:  []interface{}{7, 10},
+		},
+		{
+			name:          "nulls first",
+			options:       []Option{WithNullsOrdering(NullsFirst), WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"ASC", "ASC"})},
+			expectedQuery: "SELECT * FROM events WHERE (events.created_at > $1 OR (events.created_at = $2 AND events.id > $3)) ORDER BY events.created_at ASC NULLS FIRST, events.id ASC NULLS FIRST LIMIT $4",
+			expectedArgs:  []interface{}{createdAt, createdAt, 7, 10},
+		},
+		{
+			name:          "first page",
+			options:       []Option{WithCompositeCursor(fields, nil, []string{"DESC", "DESC"})},
+			expectedQuery: "SELECT * FROM events ORDER BY events.created_at DESC, events.id DESC LIMIT $1",
+			expectedArgs:  []interface{}{10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := mustGenerateSQL(t, append([]Option{WithTable("events"), WithStruct(Event{})}, tt.options...))
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", tt.expectedArgs, args)
+			}
+		})
+	}
+
+	for _, option := range []Option{
+		WithCompositeCursor(fields, []interface{}{createdAt}, []string{"ASC", "ASC"}),
+		WithCompositeCursor(fields, []interface{}{createdAt, 7}, []string{"ASC"}),
+		WithCompositeCursor(nil, nil, nil),
+	} {
+		if _, err := NewPaginator(WithTable("events"), WithStruct(Event{}), option); err == nil {
+			t.Error("Expected error for mismatched cursor lengths")
+		}
+	}
+}
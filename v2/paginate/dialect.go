@@ -0,0 +1,243 @@
+package paginate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies the SQL database the queries are generated for.
+type Dialect string
+
+// Supported dialects.
+const (
+	DialectPostgres  Dialect = "postgres"
+	DialectMySQL     Dialect = "mysql"
+	DialectOracle    Dialect = "oracle"
+	DialectSQLite    Dialect = "sqlite"
+	DialectSQLServer Dialect = "sqlserver"
+)
+
+// WithDialect sets the Dialect option.
+func WithDialect(dialect Dialect) Option {
+	return func(params *QueryParams) {
+		params.Dialect = dialect
+	}
+}
+
+// WithEstimatedCount sets the function building the estimated count query and
+// its arguments in Vacuum mode, with '?' placeholders. It replaces the default
+// estimate of the dialect: count_estimate on Postgres and MySQLEstimatedCount
+// on MySQL. Other dialects run the exact count without one.
+func WithEstimatedCount(estimator func(params *QueryParams) (string, []interface{})) Option {
+	return func(params *QueryParams) {
+		params.EstimatedCount = estimator
+	}
+}
+
+// MySQLEstimatedCount estimates the number of rows of the table from
+// information_schema.TABLES. The estimate ignores the filters.
+func MySQLEstimatedCount(params *QueryParams) (string, []interface{}) {
+	schema, table := params.schemaTable()
+	if schema != "" {
+		return "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?", []interface{}{schema, table}
+	}
+	return "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", []interface{}{table}
+}
+
+// estimatedCount returns the estimated count function of the params, or nil
+// when the count must be exact or estimated with count_estimate.
+func (params *QueryParams) estimatedCount() func(params *QueryParams) (string, []interface{}) {
+	if params.EstimatedCount != nil {
+		return params.EstimatedCount
+	}
+	if params.Dialect == DialectMySQL {
+		return MySQLEstimatedCount
+	}
+	return nil
+}
+
+// explainPrefix returns the EXPLAIN statement prefix of the dialect. Oracle
+// and SQL Server have no EXPLAIN statement returning the plan as rows.
+func (dialect Dialect) explainPrefix(analyze bool) (string, error) {
+	switch dialect {
+	case DialectOracle, DialectSQLServer:
+		return "", fmt.Errorf("EXPLAIN is not supported by the %s dialect", dialect)
+	case DialectSQLite:
+		// SQLite has no EXPLAIN ANALYZE, only the query plan.
+		return "EXPLAIN QUERY PLAN ", nil
+	}
+	if !analyze {
+		return "EXPLAIN ", nil
+	}
+	if dialect == DialectMySQL {
+		return "EXPLAIN ANALYZE ", nil
+	}
+	return "EXPLAIN (ANALYZE, BUFFERS) ", nil
+}
+
+// quote quotes an identifier with the quote character of the dialect.
+func (dialect Dialect) quote(identifier string) string {
+	quote := `"`
+	if dialect == DialectMySQL {
+		quote = "`"
+	}
+	return quote + strings.ReplaceAll(identifier, quote, quote+quote) + quote
+}
+
+// nullsLast reports whether the dialect sorts NULLs after the other values in
+// ascending order by default.
+func (dialect Dialect) nullsLast() bool {
+	return dialect == DialectPostgres || dialect == DialectOracle
+}
+
+// nullsSortClause returns the ORDER BY entry for the expression and direction
+// placing NULLs first or last. MySQL and SQL Server have no NULLS FIRST/LAST,
+// so NULLs are sorted by a CASE flag before the expression there. Any other
+// placement, which NewPaginator rejects, keeps the database default.
+func (dialect Dialect) nullsSortClause(expression, direction string, nulls NullsOrder) string {
+	if nulls != NullsFirst && nulls != NullsLast {
+		return expression + " " + direction
+	}
+	if dialect == DialectMySQL || dialect == DialectSQLServer {
+		flag := "1 ELSE 0"
+		if nulls == NullsFirst {
+			flag = "0 ELSE 1"
+		}
+		return "CASE WHEN " + expression + " IS NULL THEN " + flag + " END, " + expression + " " + direction
+	}
+	return expression + " " + direction + " NULLS " + string(nulls)
+}
+
+// rowValues reports whether the dialect compares row values like `(a, b) > (?, ?)`.
+func (dialect Dialect) rowValues() bool {
+	return dialect != DialectOracle && dialect != DialectSQLServer
+}
+
+// concat returns the SQL expression concatenating the parts.
+func (dialect Dialect) concat(parts ...string) string {
+	if dialect == DialectMySQL || dialect == DialectSQLServer {
+		return "CONCAT(" + strings.Join(parts, ", ") + ")"
+	}
+	return strings.Join(parts, " || ")
+}
+
+// textCast casts the expression to the text type of the dialect, so search
+// terms can be matched against columns of any type.
+func (dialect Dialect) textCast(expression string) string {
+	switch dialect {
+	case DialectMySQL:
+		return "CAST(" + expression + " AS CHAR)"
+	case DialectSQLite:
+		return "CAST(" + expression + " AS TEXT)"
+	case DialectOracle:
+		return "TO_CHAR(" + expression + ")"
+	case DialectSQLServer:
+		return "CAST(" + expression + " AS NVARCHAR(MAX))"
+	}
+	return expression + "::TEXT"
+}
+
+// jsonPath returns the expression extracting the dotted path as text from the
+// column. The keys are quoted in the JSON path of the other dialects, since
+// they may start with a digit.
+func (dialect Dialect) jsonPath(columnName, path string) string {
+	segments := strings.Split(path, ".")
+	jsonPath := `'$."` + strings.Join(segments, `"."`) + `"'`
+	switch dialect {
+	case DialectMySQL:
+		return columnName + "->>" + jsonPath
+	case DialectSQLite:
+		return "json_extract(" + columnName + ", " + jsonPath + ")"
+	case DialectOracle, DialectSQLServer:
+		return "JSON_VALUE(" + columnName + ", " + jsonPath + ")"
+	}
+	expression := columnName
+	for i, segment := range segments {
+		if i == len(segments)-1 {
+			expression += "->>'" + segment + "'"
+		} else {
+			expression += "->'" + segment + "'"
+		}
+	}
+	return expression
+}
+
+// ilike returns the case-insensitive LIKE match of the expression against the
+// pattern, lowering both sides where the dialect has no ILIKE.
+func (dialect Dialect) ilike(expression, pattern string) string {
+	switch dialect {
+	case DialectMySQL, DialectSQLite, DialectOracle, DialectSQLServer:
+		return "LOWER(" + expression + ") LIKE LOWER(" + pattern + ")"
+	}
+	return expression + " ILIKE " + pattern
+}
+
+// placeholder returns the positional placeholder of the dialect for the index.
+func (dialect Dialect) placeholder(index int) string {
+	switch dialect {
+	case DialectMySQL, DialectSQLite:
+		return "?"
+	case DialectOracle:
+		return ":" + strconv.Itoa(index)
+	case DialectSQLServer:
+		return "@p" + strconv.Itoa(index)
+	}
+	return "$" + strconv.Itoa(index)
+}
+
+// replacePlaceholders replaces '?' with the placeholders of the dialect: '$1'
+// on Postgres, '?' on MySQL and SQLite, ':1' on Oracle and '@p1' on SQL Server.
+// A placeholder whose argument is a slice is expanded into one placeholder per
+// element, with the elements flattened into the arguments.
+func (dialect Dialect) replacePlaceholders(query string, args []interface{}) (string, []interface{}) {
+	return expandPlaceholders(query, args, nil, dialect.placeholder)
+}
+
+// expandPlaceholders replaces each '?' with the placeholder returned for its
+// index, expanding slice arguments into one placeholder per element. The
+// arguments are appended to newArgs, allocated when nil.
+func expandPlaceholders(query string, args, newArgs []interface{}, placeholder func(index int) string) (string, []interface{}) {
+	var newQuery strings.Builder
+	newQuery.Grow(len(query) + 2*len(args))
+	if newArgs == nil {
+		newArgs = make([]interface{}, 0, len(args))
+	}
+	argIndex := 1
+	position := 0
+	for {
+		next := strings.IndexByte(query, '?')
+		if next < 0 {
+			newQuery.WriteString(query)
+			break
+		}
+		newQuery.WriteString(query[:next])
+		query = query[next+1:]
+
+		if position < len(args) {
+			if values, ok := sliceArg(args[position]); ok {
+				if len(values) == 0 {
+					newQuery.WriteString("NULL")
+				}
+				for i, value := range values {
+					if i > 0 {
+						newQuery.WriteString(", ")
+					}
+					newQuery.WriteString(placeholder(argIndex))
+					newArgs = append(newArgs, value)
+					argIndex++
+				}
+				position++
+				continue
+			}
+			newArgs = append(newArgs, args[position])
+		}
+		newQuery.WriteString(placeholder(argIndex))
+		argIndex++
+		position++
+	}
+	if position < len(args) {
+		newArgs = append(newArgs, args[position:]...)
+	}
+	return newQuery.String(), newArgs
+}
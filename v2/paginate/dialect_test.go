@@ -0,0 +1,264 @@
+package paginate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateExplainSQL tests the EXPLAIN prefix for each dialect.
+func TestGenerateExplainSQL(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		dialect Dialect
+		analyze bool
+		prefix  string
+	}{
+		{DialectPostgres, false, "EXPLAIN "},
+		{DialectPostgres, true, "EXPLAIN (ANALYZE, BUFFERS) "},
+		{DialectMySQL, false, "EXPLAIN "},
+		{DialectMySQL, true, "EXPLAIN ANALYZE "},
+		{DialectSQLite, false, "EXPLAIN QUERY PLAN "},
+	}
+	for _, c := range cases {
+		WithDialect(c.dialect)(p)
+		query, _ := p.GenerateSQL()
+		WithExplain(c.analyze)(p)
+
+		explainQuery, args, err := p.GenerateExplainSQL()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if explainQuery != c.prefix+query {
+			t.Errorf("Expected %s query:\n%s\nGot:\n%s", c.dialect, c.prefix+query, explainQuery)
+		}
+		if len(args) != 2 {
+			t.Errorf("Expected LIMIT and OFFSET args, got: %v", args)
+		}
+		if strings.Count(explainQuery, "EXPLAIN") != 1 {
+			t.Errorf("Expected a single EXPLAIN prefix, got: %s", explainQuery)
+		}
+	}
+
+	for _, dialect := range []Dialect{DialectOracle, DialectSQLServer} {
+		p, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSort([]string{"id"}, []string{"false"}), WithDialect(dialect))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, _, err := p.GenerateExplainSQL(); err == nil {
+			t.Errorf("Expected error for EXPLAIN on %s", dialect)
+		}
+	}
+}
+
+// TestDialectPlaceholders tests the placeholders and search predicates of each dialect in the data and count queries.
+func TestDialectPlaceholders(t *testing.T) {
+	tests := []struct {
+		dialect            Dialect
+		expectedQuery      string
+		expectedCountQuery string
+	}{
+		{
+			DialectPostgres,
+			"SELECT users.id, users.name FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2) AND age > $3 ORDER BY users.name ASC LIMIT $4 OFFSET $5",
+			"SELECT COUNT(users.id) FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2) AND age > $3",
+		},
+		{
+			DialectMySQL,
+			"SELECT users.id, users.name FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS CHAR)) LIKE LOWER(?) OR LOWER(CAST(users.email AS CHAR)) LIKE LOWER(?)) AND age > ? ORDER BY users.name ASC LIMIT ? OFFSET ?",
+			"SELECT COUNT(users.id) FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS CHAR)) LIKE LOWER(?) OR LOWER(CAST(users.email AS CHAR)) LIKE LOWER(?)) AND age > ?",
+		},
+		{
+			DialectSQLite,
+			"SELECT users.id, users.name FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS TEXT)) LIKE LOWER(?) OR LOWER(CAST(users.email AS TEXT)) LIKE LOWER(?)) AND age > ? ORDER BY users.name ASC LIMIT ? OFFSET ?",
+			"SELECT COUNT(users.id) FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS TEXT)) LIKE LOWER(?) OR LOWER(CAST(users.email AS TEXT)) LIKE LOWER(?)) AND age > ?",
+		},
+		{
+			DialectOracle,
+			"SELECT users.id, users.name FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(TO_CHAR(users.name)) LIKE LOWER(:1) OR LOWER(TO_CHAR(users.email)) LIKE LOWER(:2)) AND age > :3 ORDER BY users.name ASC OFFSET :4 ROWS FETCH NEXT :5 ROWS ONLY",
+			"SELECT COUNT(users.id) FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(TO_CHAR(users.name)) LIKE LOWER(:1) OR LOWER(TO_CHAR(users.email)) LIKE LOWER(:2)) AND age > :3",
+		},
+		{
+			DialectSQLServer,
+			"SELECT users.id, users.name FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS NVARCHAR(MAX))) LIKE LOWER(@p1) OR LOWER(CAST(users.email AS NVARCHAR(MAX))) LIKE LOWER(@p2)) AND age > @p3 ORDER BY users.name ASC OFFSET @p4 ROWS FETCH NEXT @p5 ROWS ONLY",
+			"SELECT COUNT(users.id) FROM users INNER JOIN orders ON users.id = orders.user_id WHERE (LOWER(CAST(users.name AS NVARCHAR(MAX))) LIKE LOWER(@p1) OR LOWER(CAST(users.email AS NVARCHAR(MAX))) LIKE LOWER(@p2)) AND age > @p3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithDialect(tt.dialect),
+				WithPage(2),
+				WithItemsPerPage(5),
+				WithSearch("john"),
+				WithSearchFields([]string{"name", "email"}),
+				WithSort([]string{"name"}, []string{"false"}),
+				WithWhereClause("age > ?", 30),
+				WithJoin("INNER JOIN orders ON users.id = orders.user_id"),
+				WithColumn("users.id"),
+				WithColumn("users.name"),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			query, args := p.GenerateSQL()
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			expectedArgs := []interface{}{"%john%", "%john%", 30, 5, 5}
+			if !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+			}
+			countQuery, _ := p.GenerateCountQuery()
+			if countQuery != tt.expectedCountQuery {
+				t.Errorf("Expected count query:\n%s\nGot:\n%s", tt.expectedCountQuery, countQuery)
+			}
+		})
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithDialect(DialectSQLServer)); err == nil {
+		t.Error("Expected error for SQL Server without a sort")
+	}
+
+	p, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithDialect(DialectSQLServer), WithSort([]string{"id"}, []string{"false"}), WithNoOffset(true))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ := p.GenerateSQL()
+	if !strings.HasSuffix(query, "ORDER BY users.id ASC OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY") {
+		t.Errorf("Expected OFFSET 0 ROWS before FETCH NEXT on SQL Server, got: %s", query)
+	}
+}
+
+// TestOracleDialect tests Oracle placeholders and row-limiting clause.
+func TestOracleDialect(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithDialect(DialectOracle),
+		WithPage(3),
+		WithItemsPerPage(20),
+		WithFilter("age", OpGt, 30),
+		WithSort([]string{"name"}, []string{"false"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.age > :1 ORDER BY users.name ASC OFFSET :2 ROWS FETCH NEXT :3 ROWS ONLY"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{30, 40, 20}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	countQuery, _ := p.GenerateCountQuery()
+	if countQuery != "SELECT COUNT(users.id) FROM users WHERE users.age > :1" {
+		t.Errorf("Expected Oracle placeholders in count query, got: %s", countQuery)
+	}
+
+	WithNoOffset(true)(p)
+	query, _ = p.GenerateSQL()
+	if !strings.HasSuffix(query, "ORDER BY users.name ASC FETCH NEXT :2 ROWS ONLY") {
+		t.Errorf("Expected FETCH NEXT without OFFSET, got: %s", query)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithDialect(DialectOracle),
+	)
+	if err == nil || !strings.Contains(err.Error(), "oracle dialect requires a sort") {
+		t.Errorf("Expected missing sort error, got: %v", err)
+	}
+}
+
+// TestWithEstimatedCount tests the estimated count queries of non-Postgres dialects in vacuum mode.
+func TestWithEstimatedCount(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users u"),
+		WithStruct(User{}),
+		WithVacuum(true),
+		WithDialect(DialectMySQL),
+		WithFilter("age", OpGte, 18),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateCountQuery()
+	expectedQuery := "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"users"}) {
+		t.Errorf("Expected args: [users]\nGot: %v", args)
+	}
+
+	WithEstimatedCount(func(params *QueryParams) (string, []interface{}) {
+		return "SELECT estimate FROM table_stats WHERE name = ?", []interface{}{params.Table}
+	})(p)
+	WithDialect(DialectOracle)(p)
+	query, args = p.GenerateCountQuery()
+	expectedQuery = "SELECT estimate FROM table_stats WHERE name = :1"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"users u"}) {
+		t.Errorf("Expected args: [users u]\nGot: %v", args)
+	}
+
+	// Without an estimator, Oracle falls back to the exact count.
+	p.EstimatedCount = nil
+	query, _ = p.GenerateCountQuery()
+	expectedQuery = "SELECT COUNT(users.id) FROM users u WHERE users.age >= :1"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestWithSelectDialect tests selecting the expression of the active dialect.
+func TestWithSelectDialect(t *testing.T) {
+	fullName := WithSelectDialect(map[Dialect]string{
+		DialectPostgres: "users.name || ' ' || users.email",
+		DialectMySQL:    "CONCAT(users.name, ' ', users.email)",
+	}, "label")
+
+	tests := []struct {
+		dialect       Dialect
+		expectedQuery string
+	}{
+		{DialectPostgres, "SELECT users.id, users.name || ' ' || users.email AS label FROM users LIMIT $1 OFFSET $2"},
+		{DialectMySQL, "SELECT users.id, CONCAT(users.name, ' ', users.email) AS label FROM users LIMIT ? OFFSET ?"},
+	}
+
+	for _, tt := range tests {
+		p, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithColumn("users.id"), fullName, WithDialect(tt.dialect))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		query, _ := p.GenerateSQL()
+		if query != tt.expectedQuery {
+			t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+		}
+	}
+
+	_, err := NewPaginator(WithTable("users"), WithStruct(User{}), fullName, WithDialect(DialectOracle), WithSort([]string{"id"}, []string{"false"}))
+	if err == nil || !strings.Contains(err.Error(), "no oracle expression") {
+		t.Errorf("Expected a missing expression error, got: %v", err)
+	}
+}
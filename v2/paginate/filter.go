@@ -0,0 +1,651 @@
+package paginate
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operator is the comparison applied by a Filter.
+type Operator string
+
+// Supported filter operators.
+const (
+	OpEq         Operator = "eq"
+	OpNeq        Operator = "neq"
+	OpGt         Operator = "gt"
+	OpGte        Operator = "gte"
+	OpLt         Operator = "lt"
+	OpLte        Operator = "lte"
+	OpLike       Operator = "like"
+	OpIn         Operator = "in"
+	OpNotIn      Operator = "notin"
+	OpInI        Operator = "ini"
+	OpBetween    Operator = "between"
+	OpNotBetween Operator = "notbetween"
+	OpIsNull     Operator = "isnull"
+	OpIsNotNull  Operator = "isnotnull"
+)
+
+// Filter is a condition on a struct field, resolved to its column through the
+// struct tags. Or joins it to the previous filter with OR instead of AND.
+type Filter struct {
+	Field    string
+	Operator Operator
+	Values   []interface{}
+	Or       bool
+}
+
+// WithEqualsMap adds an equality filter for each field of the map, in sorted
+// field order so the generated SQL is deterministic.
+func WithEqualsMap(values map[string]interface{}) Option {
+	return func(params *QueryParams) {
+		fields := make([]string, 0, len(values))
+		for field := range values {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			params.Filters = append(params.Filters, Filter{Field: field, Operator: OpEq, Values: []interface{}{values[field]}, Or: params.orNext})
+			params.orNext = false
+		}
+	}
+}
+
+// ColumnComparison compares two struct fields, resolved to their columns through the struct tags.
+type ColumnComparison struct {
+	Left     string
+	Operator string
+	Right    string
+}
+
+// WithFilter adds a filter on the field using the operator and values.
+func WithFilter(field string, operator Operator, values ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.Filters = append(params.Filters, Filter{Field: field, Operator: operator, Values: values, Or: params.orNext})
+		params.orNext = false
+	}
+}
+
+// WithFilterInI adds a case-insensitive IN filter on the text field, emitting
+// LOWER(column) IN (LOWER(?), ...), so "Active" matches "active".
+func WithFilterInI(field string, values ...interface{}) Option {
+	return WithFilter(field, OpInI, values...)
+}
+
+// WithOr joins the next filter, of WithFilter, WithFieldAnyOf, WithEqualsMap
+// or WithFilterStruct, to the previous filter with OR instead of AND, e.g.
+// WithFilter("name", OpEq, "John"), WithOr(), WithFilter("age", OpGt, 65)
+// emits (name = $1 OR age > $2). A WithOr no filter follows is an error.
+func WithOr() Option {
+	return func(params *QueryParams) {
+		params.orNext = true
+	}
+}
+
+// FieldCondition is an operator and its values, applied to a field by WithFieldAnyOf.
+type FieldCondition struct {
+	Operator Operator
+	Values   []interface{}
+}
+
+// Is returns a FieldCondition for WithFieldAnyOf.
+func Is(operator Operator, values ...interface{}) FieldCondition {
+	return FieldCondition{Operator: operator, Values: values}
+}
+
+// WithFieldAnyOf adds a single parenthesized group matching any of the
+// conditions on the field, e.g. WithFieldAnyOf("price", Is(OpGte, 100), Is(OpIsNull))
+// emits (price >= $1 OR price IS NULL).
+func WithFieldAnyOf(field string, conditions ...FieldCondition) Option {
+	return func(params *QueryParams) {
+		for i, condition := range conditions {
+			params.Filters = append(params.Filters, Filter{Field: field, Operator: condition.Operator, Values: condition.Values, Or: i > 0 || params.orNext})
+		}
+		if len(conditions) > 0 {
+			params.orNext = false
+		}
+	}
+}
+
+// WithFilterStruct adds a filter for each non-zero field of a typed filter
+// struct, tagged with the operator and the filtered field, e.g.
+//
+//	type UserFilter struct {
+//		MinAge int    `filter:"gte,age"`
+//		Name   string `filter:"like,name"`
+//	}
+//
+// Pointer fields filter when non-nil, so zero values can be matched, and slice
+// fields spread into the values of in, notin, between and notbetween.
+func WithFilterStruct(filters interface{}) Option {
+	return func(params *QueryParams) {
+		value := reflect.Indirect(reflect.ValueOf(filters))
+		if value.Kind() != reflect.Struct {
+			params.errs = append(params.errs, fmt.Errorf("invalid filter struct %T", filters))
+			return
+		}
+		for i := 0; i < value.NumField(); i++ {
+			tag, ok := value.Type().Field(i).Tag.Lookup("filter")
+			if !ok {
+				continue
+			}
+			operator, field, ok := strings.Cut(tag, ",")
+			if !ok || field == "" {
+				params.errs = append(params.errs, fmt.Errorf("invalid filter tag %q on %s", tag, value.Type().Field(i).Name))
+				continue
+			}
+			fieldValue := value.Field(i)
+			if fieldValue.IsZero() {
+				continue
+			}
+			fieldValue = reflect.Indirect(fieldValue)
+
+			var values []interface{}
+			if fieldValue.Kind() == reflect.Slice {
+				for j := 0; j < fieldValue.Len(); j++ {
+					values = append(values, fieldValue.Index(j).Interface())
+				}
+			} else {
+				values = []interface{}{fieldValue.Interface()}
+			}
+			params.Filters = append(params.Filters, Filter{Field: field, Operator: Operator(operator), Values: values, Or: params.orNext})
+			params.orNext = false
+		}
+	}
+}
+
+// WithColumnComparison adds a condition comparing two fields, e.g. start_date < end_date.
+// The operator must be one of =, <>, !=, <, <=, > or >=.
+func WithColumnComparison(leftField, operator, rightField string) Option {
+	return func(params *QueryParams) {
+		params.ColumnComparisons = append(params.ColumnComparisons, ColumnComparison{Left: leftField, Operator: operator, Right: rightField})
+	}
+}
+
+// WithCoalesce makes the filters on the field treat NULL as the default value,
+// wrapping the column in COALESCE(column, default). IS NULL checks are left as is.
+func WithCoalesce(field string, defaultValue interface{}) Option {
+	return func(params *QueryParams) {
+		if params.Coalesce == nil {
+			params.Coalesce = map[string]interface{}{}
+		}
+		params.Coalesce[field] = defaultValue
+	}
+}
+
+// known reports whether the operator is one of the supported operators.
+func (operator Operator) known() bool {
+	switch operator {
+	case OpEq, OpNeq, OpGt, OpGte, OpLt, OpLte, OpLike, OpIn, OpNotIn, OpInI,
+		OpBetween, OpNotBetween, OpIsNull, OpIsNotNull:
+		return true
+	}
+	return false
+}
+
+// validate checks the operator is supported and receives the expected number of values.
+func (filter Filter) validate() error {
+	expected := -1
+	switch filter.Operator {
+	case OpEq, OpNeq, OpGt, OpGte, OpLt, OpLte, OpLike:
+		expected = 1
+	case OpBetween, OpNotBetween:
+		expected = 2
+	case OpIsNull, OpIsNotNull:
+		expected = 0
+	case OpIn, OpNotIn, OpInI:
+		return nil
+	default:
+		return fmt.Errorf("unsupported operator %q for field %q", filter.Operator, filter.Field)
+	}
+	if len(filter.Values) != expected {
+		return fmt.Errorf("operator %q for field %q expects %d value(s), got %d", filter.Operator, filter.Field, expected, len(filter.Values))
+	}
+	return nil
+}
+
+// filterPredicate returns the SQL condition of the filter for the column and its arguments.
+func (params *QueryParams) filterPredicate(filter Filter, columnName string) (string, []interface{}) {
+	switch filter.Operator {
+	case OpEq:
+		return columnName + " = ?", filter.Values
+	case OpNeq:
+		return columnName + " <> ?", filter.Values
+	case OpGt:
+		return columnName + " > ?", filter.Values
+	case OpGte:
+		return columnName + " >= ?", filter.Values
+	case OpLt:
+		return columnName + " < ?", filter.Values
+	case OpLte:
+		return columnName + " <= ?", filter.Values
+	case OpLike:
+		clause, arg := params.likePredicate(columnName, fmt.Sprint(filter.Values[0]), true, true)
+		return clause, []interface{}{arg}
+	case OpIn:
+		return columnName + " IN (?)", []interface{}{filter.Values}
+	case OpNotIn:
+		// Excluding no values matches every row, where NOT IN (NULL) matches none.
+		if len(expandedValues(filter.Values)) == 0 {
+			return "1 = 1", nil
+		}
+		return columnName + " NOT IN (?)", []interface{}{filter.Values}
+	case OpInI:
+		values := expandedValues(filter.Values)
+		if len(values) == 0 {
+			return columnName + " IN (NULL)", nil
+		}
+		return "LOWER(" + columnName + ") IN (" + strings.TrimSuffix(strings.Repeat("LOWER(?), ", len(values)), ", ") + ")", values
+	case OpBetween:
+		return columnName + " BETWEEN ? AND ?", filter.Values
+	case OpNotBetween:
+		// A nil bound leaves the range open on that side.
+		low, high := filter.Values[0], filter.Values[1]
+		switch {
+		case low == nil && high == nil:
+			return "", nil
+		case low == nil:
+			return columnName + " > ?", []interface{}{high}
+		case high == nil:
+			return columnName + " < ?", []interface{}{low}
+		}
+		return columnName + " NOT BETWEEN ? AND ?", filter.Values
+	case OpIsNull:
+		return columnName + " IS NULL", nil
+	case OpIsNotNull:
+		return columnName + " IS NOT NULL", nil
+	}
+	return "", nil
+}
+
+// buildFilterClauses constructs the conditions of the valid and resolvable
+// filters. OR groups follow the given filters, so a dropped filter never moves
+// the next Or filter into the previous group.
+func (params *QueryParams) buildFilterClauses(filters []Filter) ([]string, []interface{}) {
+	return params.filterClausesIn(filters, nil)
+}
+
+// filterClausesIn constructs the filter conditions in the buffers when set.
+func (params *QueryParams) filterClausesIn(filters []Filter, buffers *sqlBuffers) ([]string, []interface{}) {
+	var groups [][]string
+	var clauses []string
+	var args []interface{}
+	if buffers != nil {
+		groups, clauses, args = buffers.filterGroups[:0], buffers.filterClauses[:0], buffers.filterArgs[:0]
+	}
+	for _, filter := range filters {
+		if !filter.Or || len(groups) == 0 {
+			// Reuse the slice of a pooled group instead of a new one.
+			if len(groups) < cap(groups) {
+				groups = groups[:len(groups)+1]
+				groups[len(groups)-1] = groups[len(groups)-1][:0]
+			} else {
+				groups = append(groups, nil)
+			}
+		}
+		columnName := params.guardedColumnName(filter.Field, string(filter.Operator))
+		if columnName == "" || filter.validate() != nil {
+			continue
+		}
+		defaultValue, coalesce := params.Coalesce[filter.Field]
+		coalesce = coalesce && filter.Operator != OpIsNull && filter.Operator != OpIsNotNull
+		if coalesce {
+			columnName = "COALESCE(" + columnName + ", ?)"
+		}
+		filter.Values = params.redactedValues(filter.Field, filter.Values)
+		clause, filterArgs := params.filterPredicate(filter, columnName)
+		if clause == "" {
+			continue
+		}
+		// Predicates without the column, like an empty notin, bind no default.
+		if coalesce && strings.Contains(clause, columnName) {
+			args = append(args, defaultValue)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], clause)
+		args = append(args, filterArgs...)
+	}
+
+	if clauses == nil {
+		clauses = make([]string, 0, len(groups))
+	}
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if len(group) == 1 {
+			clauses = append(clauses, group[0])
+		} else {
+			clauses = append(clauses, params.group(group, "OR"))
+		}
+	}
+	if buffers != nil {
+		buffers.filterGroups = append(buffers.filterGroups[:0], groups...)
+		buffers.filterClauses, buffers.filterArgs = clauses, args
+	}
+	return clauses, args
+}
+
+// validateRanges returns an error for a between filter whose low bound is
+// greater than its high bound, and for a lower bound (gt, gte) greater than an
+// upper bound (lt, lte) on the same field. Filters in OR groups are skipped.
+func validateRanges(filters []Filter) error {
+	lower := map[string][]interface{}{}
+	upper := map[string][]interface{}{}
+	for i, filter := range filters {
+		if filter.Or || (i+1 < len(filters) && filters[i+1].Or) || filter.validate() != nil {
+			continue
+		}
+		switch filter.Operator {
+		case OpBetween, OpNotBetween:
+			if c, ok := compareValues(filter.Values[0], filter.Values[1]); ok && c > 0 {
+				return fmt.Errorf("reversed %s range for field %q: %v > %v", filter.Operator, filter.Field, filter.Values[0], filter.Values[1])
+			}
+		case OpGt, OpGte:
+			lower[filter.Field] = append(lower[filter.Field], filter.Values[0])
+		case OpLt, OpLte:
+			upper[filter.Field] = append(upper[filter.Field], filter.Values[0])
+		}
+	}
+	for _, filter := range filters {
+		for _, low := range lower[filter.Field] {
+			for _, high := range upper[filter.Field] {
+				if c, ok := compareValues(low, high); ok && c > 0 {
+					return fmt.Errorf("reversed range for field %q: lower bound %v > upper bound %v", filter.Field, low, high)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// compareValues compares two numbers, times or strings, reporting false when
+// they aren't comparable. Numeric strings, like query param values, are
+// compared as numbers.
+func compareValues(a, b interface{}) (int, bool) {
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+		return 0, false
+	}
+	x, xOk := numericValue(a)
+	y, yOk := numericValue(b)
+	if xOk && yOk {
+		return cmp.Compare(x, y), true
+	}
+	if xs, ok := a.(string); ok {
+		if ys, ok := b.(string); ok {
+			return strings.Compare(xs, ys), true
+		}
+	}
+	return 0, false
+}
+
+// numericValue returns the value as a float64 when it is a number or a numeric string.
+func numericValue(value interface{}) (float64, bool) {
+	if s, ok := value.(string); ok {
+		f, err := strconv.ParseFloat(s, 64)
+		return f, err == nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// hasOrFilters reports whether any filter is joined to the previous one with OR.
+func hasOrFilters(filters []Filter) bool {
+	for _, filter := range filters {
+		if filter.Or {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks the comparison operator is allowed.
+func (comparison ColumnComparison) validate() error {
+	switch comparison.Operator {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		return nil
+	}
+	return fmt.Errorf("unsupported comparison operator %q between %q and %q", comparison.Operator, comparison.Left, comparison.Right)
+}
+
+// buildColumnComparisonClauses constructs the conditions of the valid and resolvable column comparisons.
+func (params *QueryParams) buildColumnComparisonClauses() []string {
+	var clauses []string
+	for _, comparison := range params.ColumnComparisons {
+		left := params.guardedColumnName(comparison.Left, "compare")
+		right := params.guardedColumnName(comparison.Right, "compare")
+		if left == "" || right == "" || comparison.validate() != nil {
+			continue
+		}
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", left, comparison.Operator, right))
+	}
+	return clauses
+}
+
+// Condition is a normalized view of a condition applied by the params. Or
+// marks the conditions joined with OR: filters after WithOr and the WithOrFilter
+// conditions ORed with the search group.
+type Condition struct {
+	Op     string
+	Field  string
+	Values []interface{}
+	Or     bool
+}
+
+// Conditions returns every applied condition: default filters, search, the
+// filters ORed with it, filters, column comparisons and custom where clauses.
+// Conditions on fields that can't
+// be resolved are left out, since they aren't applied. Search conditions use
+// the "search_<mode>" op (or "search_any" for multi-term searches, with the
+// terms as values), column comparisons the "compare" op with the
+// operator and the right field as values, and custom where clauses the "where"
+// op with the clause as field.
+func (params *QueryParams) Conditions() []Condition {
+	var conditions []Condition
+
+	if len(params.DefaultFilters) > 0 {
+		defaults := &QueryParams{}
+		for _, option := range params.DefaultFilters {
+			option(defaults)
+		}
+		conditions = append(conditions, params.filterConditions(defaults.Filters)...)
+		conditions = append(conditions, whereConditions(defaults.WhereClauses, defaults.WhereArgs)...)
+	}
+
+	searches := len(conditions)
+	if params.Search != "" {
+		for _, field := range params.SearchFields {
+			if params.guardedColumnName(field, "search") != "" {
+				conditions = append(conditions, Condition{Op: "search_" + SearchContains.String(), Field: field, Values: []interface{}{params.Search}})
+			}
+		}
+	}
+	for _, condition := range params.SearchConditions {
+		if params.guardedColumnName(condition.Field, "search") != "" {
+			conditions = append(conditions, Condition{Op: "search_" + condition.Mode.String(), Field: condition.Field, Values: []interface{}{condition.Term}})
+		}
+	}
+	if len(conditions) > searches && len(params.OrFilters) > 0 {
+		orFilters := params.orFilterParams()
+		orConditions := append(params.filterConditions(orFilters.Filters), whereConditions(orFilters.WhereClauses, orFilters.WhereArgs)...)
+		for _, condition := range orConditions {
+			condition.Or = true
+			conditions = append(conditions, condition)
+		}
+	}
+
+	for _, multiSearch := range params.MultiSearches {
+		for _, field := range multiSearch.Fields {
+			if params.guardedColumnName(field, "search") != "" && len(multiSearch.Terms) > 0 {
+				values := make([]interface{}, len(multiSearch.Terms))
+				for i, term := range multiSearch.Terms {
+					values[i] = term
+				}
+				conditions = append(conditions, Condition{Op: "search_any", Field: field, Values: values})
+			}
+		}
+	}
+
+	conditions = append(conditions, params.filterConditions(params.Filters)...)
+
+	for _, comparison := range params.ColumnComparisons {
+		if params.guardedColumnName(comparison.Left, "compare") != "" && params.guardedColumnName(comparison.Right, "compare") != "" && comparison.validate() == nil {
+			conditions = append(conditions, Condition{Op: "compare", Field: comparison.Left, Values: []interface{}{comparison.Operator, comparison.Right}})
+		}
+	}
+
+	return append(conditions, whereConditions(params.WhereClauses, params.WhereArgs)...)
+}
+
+// filterConditions returns the conditions of the valid and resolvable filters.
+func (params *QueryParams) filterConditions(filters []Filter) []Condition {
+	var conditions []Condition
+	for _, filter := range filters {
+		if params.guardedColumnName(filter.Field, string(filter.Operator)) != "" && filter.validate() == nil {
+			conditions = append(conditions, Condition{Op: string(filter.Operator), Field: filter.Field, Values: filter.Values, Or: filter.Or})
+		}
+	}
+	return conditions
+}
+
+// whereConditions splits the flattened where arguments between their clauses
+// by counting the placeholders of each clause.
+func whereConditions(clauses []string, args []interface{}) []Condition {
+	var conditions []Condition
+	for _, clause := range clauses {
+		count := strings.Count(clause, "?")
+		if count > len(args) {
+			count = len(args)
+		}
+		conditions = append(conditions, Condition{Op: "where", Field: clause, Values: args[:count:count]})
+		args = args[count:]
+	}
+	return conditions
+}
+
+// DescribeFilters returns a readable description of each valid filter, e.g.
+// "age >= 18" or "status in (active, pending)", for filter chips and search
+// summaries.
+func (params *QueryParams) DescribeFilters() []string {
+	var descriptions []string
+	for _, filter := range params.Filters {
+		if filter.validate() != nil {
+			continue
+		}
+		descriptions = append(descriptions, filter.describe())
+	}
+	return descriptions
+}
+
+// expandedValues returns the values with the slice values expanded into their
+// elements.
+func expandedValues(values []interface{}) []interface{} {
+	var expanded []interface{}
+	for _, value := range values {
+		if items, ok := sliceArg(value); ok {
+			expanded = append(expanded, items...)
+		} else {
+			expanded = append(expanded, value)
+		}
+	}
+	return expanded
+}
+
+// describe returns the readable description of a valid filter, falling back to
+// the operator and its values when they don't fit the readable form.
+func (filter Filter) describe() string {
+	var values []string
+	for _, value := range filter.Values {
+		if items, ok := sliceArg(value); ok {
+			for _, item := range items {
+				values = append(values, fmt.Sprint(item))
+			}
+			continue
+		}
+		values = append(values, fmt.Sprint(value))
+	}
+	// Slice values are expanded, so their count can still differ from the
+	// operator's, e.g. an empty slice for eq.
+	switch filter.Operator {
+	case OpEq, OpNeq, OpGt, OpGte, OpLt, OpLte, OpLike:
+		if len(values) != 1 {
+			return filter.Field + " " + string(filter.Operator) + " (" + strings.Join(values, ", ") + ")"
+		}
+	case OpBetween, OpNotBetween:
+		if len(values) != 2 {
+			return filter.Field + " " + string(filter.Operator) + " (" + strings.Join(values, ", ") + ")"
+		}
+	}
+	switch filter.Operator {
+	case OpEq:
+		return filter.Field + " = " + values[0]
+	case OpNeq:
+		return filter.Field + " != " + values[0]
+	case OpGt:
+		return filter.Field + " > " + values[0]
+	case OpGte:
+		return filter.Field + " >= " + values[0]
+	case OpLt:
+		return filter.Field + " < " + values[0]
+	case OpLte:
+		return filter.Field + " <= " + values[0]
+	case OpLike:
+		return filter.Field + " contains " + values[0]
+	case OpIn:
+		return filter.Field + " in (" + strings.Join(values, ", ") + ")"
+	case OpNotIn:
+		return filter.Field + " not in (" + strings.Join(values, ", ") + ")"
+	case OpInI:
+		return filter.Field + " in (" + strings.Join(values, ", ") + ") ignoring case"
+	case OpBetween:
+		return filter.Field + " between " + values[0] + " and " + values[1]
+	case OpNotBetween:
+		return filter.Field + " not between " + values[0] + " and " + values[1]
+	case OpIsNull:
+		return filter.Field + " is empty"
+	}
+	return filter.Field + " is not empty"
+}
+
+// FieldDoc documents a filterable and sortable field of a model.
+type FieldDoc struct {
+	Name   string
+	Column string
+	Type   string
+}
+
+// FilterableFields returns the json name, column and Go type of each field of
+// the model with both a json and a paginate tag, the fields the filters and
+// sorts resolve, for generating API docs. It returns nil for a non-struct.
+func FilterableFields(model interface{}) []FieldDoc {
+	rt, err := structType(model)
+	if err != nil {
+		return nil
+	}
+	var docs []FieldDoc
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		column := field.Tag.Get("paginate")
+		if jsonName == "" || jsonName == "-" || column == "" {
+			continue
+		}
+		docs = append(docs, FieldDoc{Name: jsonName, Column: column, Type: field.Type.String()})
+	}
+	return docs
+}
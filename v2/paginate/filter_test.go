@@ -0,0 +1,629 @@
+package paginate
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWithFilter tests the SQL generated for each filter operator.
+func TestWithFilter(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("name", OpEq, "john"),
+		WithFilter("name", OpNeq, "jane"),
+		WithFilter("age", OpGt, 18),
+		WithFilter("age", OpGte, 21),
+		WithFilter("age", OpLt, 65),
+		WithFilter("age", OpLte, 60),
+		WithFilter("email", OpLike, "example"),
+		WithFilter("id", OpIn, 1, 2, 3),
+		WithFilter("id", OpNotIn, 4),
+		WithFilter("age", OpBetween, 30, 40),
+		WithFilter("email", OpIsNotNull),
+		WithFilter("nonexistent", OpIsNull),
+		WithWhereClause("age <> ?", 50),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.name = $1 AND users.name <> $2 AND users.age > $3 AND users.age >= $4 AND users.age < $5 AND users.age <= $6 AND users.email::TEXT ILIKE $7 AND users.id IN ($8, $9, $10) AND users.id NOT IN ($11) AND users.age BETWEEN $12 AND $13 AND users.email IS NOT NULL AND age <> $14 LIMIT $15 OFFSET $16"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	expectedArgs := []interface{}{"john", "jane", 18, 21, 65, 60, "%example%", 1, 2, 3, 4, 30, 40, 50, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	countQuery, _ := p.GenerateCountQuery()
+	if !strings.Contains(countQuery, "WHERE users.name = $1 AND") {
+		t.Errorf("Expected filters in the count query, got: %s", countQuery)
+	}
+}
+
+// TestWithFilterValidation tests invalid filter operators and values.
+func TestWithFilterValidation(t *testing.T) {
+	_, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("age", Operator("approx"), 30),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unsupported operator "approx"`) {
+		t.Errorf("Expected unsupported operator error, got: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("age", OpBetween, 30),
+	)
+	if err == nil || !strings.Contains(err.Error(), "expects 2 value(s), got 1") {
+		t.Errorf("Expected value count error, got: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRejectUnknownFields(),
+		WithFilter("salary", OpGte, 5),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "salary"`) {
+		t.Errorf("Expected unknown field error, got: %v", err)
+	}
+}
+
+// TestWithFilterEmptyNotIn tests that excluding no values matches every row.
+func TestWithFilterEmptyNotIn(t *testing.T) {
+	query, args := mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("id", OpNotIn, []int{}),
+		WithFilter("age", OpGte, 18),
+	})
+	expectedQuery := "SELECT * FROM users WHERE 1 = 1 AND users.age >= $1 LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// The coalesce default isn't bound without the column in the predicate.
+	query, args = mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithCoalesce("name", "none"),
+		WithFilter("name", OpNotIn, []string{}),
+		WithFilter("age", OpGt, 18),
+	})
+	expectedQuery = "SELECT * FROM users WHERE 1 = 1 AND users.age > $1 LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithColumnComparison tests conditions comparing two columns.
+func TestWithColumnComparison(t *testing.T) {
+	type Event struct {
+		ID        int    `json:"id" paginate:"events.id"`
+		StartDate string `json:"start_date" paginate:"events.start_date"`
+		EndDate   string `json:"end_date" paginate:"events.end_date"`
+	}
+
+	p, err := NewPaginator(
+		WithTable("events"),
+		WithStruct(Event{}),
+		WithFilter("id", OpGt, 100),
+		WithColumnComparison("start_date", "<", "end_date"),
+		WithColumnComparison("start_date", "<>", "nonexistent"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM events WHERE events.id > $1 AND events.start_date < events.end_date LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{100, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	_, err = NewPaginator(
+		WithTable("events"),
+		WithStruct(Event{}),
+		WithColumnComparison("start_date", "< 1; DROP TABLE events; --", "end_date"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "unsupported comparison operator") {
+		t.Errorf("Expected unsupported comparison operator error, got: %v", err)
+	}
+}
+
+// TestConditions tests the normalized list of applied conditions.
+func TestConditions(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithDefaultFilters(WithFilter("age", OpGte, 18)),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "nonexistent"}),
+		WithSearchField("email", SearchPrefix, "jo"),
+		WithFilter("name", OpEq, "john"),
+		WithFilter("id", OpIn, 1, 2),
+		WithFilter("age", OpBetween, 20, 30),
+		WithFilter("email", OpIsNull),
+		WithFilter("salary", OpGt, 1000),
+		WithColumnComparison("id", "<", "age"),
+		WithWhereClause("users.age > ? AND users.age < ?", 20, 60),
+		WithWhereClause("users.deleted_at IS NULL"),
+		WithWhereClause("users.role IN (?)", []string{"admin", "staff"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Condition{
+		{Op: "gte", Field: "age", Values: []interface{}{18}},
+		{Op: "search_contains", Field: "name", Values: []interface{}{"john"}},
+		{Op: "search_prefix", Field: "email", Values: []interface{}{"jo"}},
+		{Op: "eq", Field: "name", Values: []interface{}{"john"}},
+		{Op: "in", Field: "id", Values: []interface{}{1, 2}},
+		{Op: "between", Field: "age", Values: []interface{}{20, 30}},
+		{Op: "isnull", Field: "email", Values: nil},
+		{Op: "compare", Field: "id", Values: []interface{}{"<", "age"}},
+		{Op: "where", Field: "users.age > ? AND users.age < ?", Values: []interface{}{20, 60}},
+		{Op: "where", Field: "users.deleted_at IS NULL", Values: []interface{}{}},
+		{Op: "where", Field: "users.role IN (?)", Values: []interface{}{[]string{"admin", "staff"}}},
+	}
+	conditions := p.Conditions()
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected conditions:\n%+v\nGot:\n%+v", expected, conditions)
+	}
+
+	// Filters joined with OR, and the ones ORed with the search group, are marked.
+	p, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithOrFilter(WithFilter("age", OpGt, 65), WithWhereClause("users.featured")),
+		WithFilter("id", OpEq, 1),
+		WithOr(),
+		WithFilter("id", OpEq, 2),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = []Condition{
+		{Op: "search_contains", Field: "name", Values: []interface{}{"john"}},
+		{Op: "gt", Field: "age", Values: []interface{}{65}, Or: true},
+		{Op: "where", Field: "users.featured", Values: nil, Or: true},
+		{Op: "eq", Field: "id", Values: []interface{}{1}},
+		{Op: "eq", Field: "id", Values: []interface{}{2}, Or: true},
+	}
+	conditions = p.Conditions()
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected conditions:\n%+v\nGot:\n%+v", expected, conditions)
+	}
+}
+
+// TestWithFieldGuard tests dropping fields rejected by the field guard.
+func TestWithFieldGuard(t *testing.T) {
+	type Employee struct {
+		ID     int     `json:"id" paginate:"employees.id"`
+		Name   string  `json:"name" paginate:"employees.name"`
+		Salary float64 `json:"salary" paginate:"employees.salary"`
+	}
+
+	var checked []string
+	p, err := NewPaginator(
+		WithTable("employees"),
+		WithStruct(Employee{}),
+		WithFilter("name", OpEq, "john"),
+		WithFilter("salary", OpGte, 5000),
+		WithSort([]string{"salary", "name"}, []string{"true", "false"}),
+		WithFieldGuard(func(field, op string) bool {
+			checked = append(checked, op+":"+field)
+			return field != "salary"
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM employees WHERE employees.name = $1 ORDER BY employees.name ASC LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	expectedChecked := []string{"eq:name", "gte:salary", "sort:salary", "sort:name"}
+	if !reflect.DeepEqual(checked, expectedChecked) {
+		t.Errorf("Expected guard calls: %v\nGot: %v", expectedChecked, checked)
+	}
+
+	// Default filters are mandatory, so the guard doesn't drop them.
+	query, _ = mustGenerateSQL(t, []Option{
+		WithTable("employees"),
+		WithStruct(Employee{}),
+		WithDefaultFilters(WithFilter("salary", OpLt, 9000)),
+		WithFilter("salary", OpGte, 5000),
+		WithFieldGuard(func(field, op string) bool { return field != "salary" }),
+	})
+	if !strings.Contains(query, "WHERE employees.salary < $1 LIMIT") {
+		t.Errorf("Expected the guarded default filter kept, got: %s", query)
+	}
+}
+
+// TestWithCoalesce tests wrapping filtered columns in COALESCE.
+func TestWithCoalesce(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithCoalesce("age", 0),
+		WithFilter("age", OpGte, 0),
+		WithFilter("age", OpBetween, 0, 30),
+		WithFilter("age", OpIsNotNull),
+		WithFilter("name", OpEq, "john"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE COALESCE(users.age, $1) >= $2 AND COALESCE(users.age, $3) BETWEEN $4 AND $5 AND users.age IS NOT NULL AND users.name = $6 LIMIT $7 OFFSET $8"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{0, 0, 0, 0, 30, "john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithEqualsMap tests equality filters from a map in stable order.
+func TestWithEqualsMap(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		p, err := NewPaginator(
+			WithTable("users"),
+			WithStruct(User{}),
+			WithEqualsMap(map[string]interface{}{
+				"name":  "john",
+				"age":   30,
+				"email": "john@example.com",
+				"id":    7,
+			}),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		query, args := p.GenerateSQL()
+		expectedQuery := "SELECT * FROM users WHERE users.age = $1 AND users.email = $2 AND users.id = $3 AND users.name = $4 LIMIT $5 OFFSET $6"
+		if query != expectedQuery {
+			t.Fatalf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+		}
+		expectedArgs := []interface{}{30, "john@example.com", 7, "john", 10, 0}
+		if !reflect.DeepEqual(args, expectedArgs) {
+			t.Fatalf("Expected args: %v\nGot: %v", expectedArgs, args)
+		}
+	}
+}
+
+// TestWithFilterNotBetween tests NOT BETWEEN filters and their open-ended bounds.
+func TestWithFilterNotBetween(t *testing.T) {
+	tests := []struct {
+		name          string
+		low, high     interface{}
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{"both bounds", 13, 17, "SELECT * FROM users WHERE users.age NOT BETWEEN $1 AND $2 LIMIT $3 OFFSET $4", []interface{}{13, 17, 10, 0}},
+		{"nil low bound", nil, 17, "SELECT * FROM users WHERE users.age > $1 LIMIT $2 OFFSET $3", []interface{}{17, 10, 0}},
+		{"nil high bound", 13, nil, "SELECT * FROM users WHERE users.age < $1 LIMIT $2 OFFSET $3", []interface{}{13, 10, 0}},
+		{"nil bounds", nil, nil, "SELECT * FROM users LIMIT $1 OFFSET $2", []interface{}{10, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithFilter("age", OpNotBetween, tt.low, tt.high),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			query, args := p.GenerateSQL()
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", tt.expectedArgs, args)
+			}
+		})
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithFilter("age", OpNotBetween, 13)); err == nil {
+		t.Error("Expected error for a single NOT BETWEEN bound")
+	}
+}
+
+// TestWithOr tests joining filters with OR.
+func TestWithOr(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("email", OpIsNotNull),
+		WithFilter("name", OpEq, "John"),
+		WithOr(),
+		WithFilter("age", OpGt, 65),
+		WithOr(),
+		WithFilter("age", OpLt, 18),
+		WithFilter("id", OpNeq, 1),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.email IS NOT NULL AND (users.name = $1 OR users.age > $2 OR users.age < $3) AND users.id <> $4 LIMIT $5 OFFSET $6"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"John", 65, 18, 1, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// A dropped filter keeps the next Or filter in its own group.
+	query, args = mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("name", OpEq, "bob"),
+		WithFilter("salary", OpGt, 100),
+		WithOr(),
+		WithFilter("age", OpGt, 65),
+	})
+	expectedQuery = "SELECT * FROM users WHERE users.name = $1 AND users.age > $2 LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"bob", 65, 10, 0}) {
+		t.Errorf("Expected args [bob 65 10 0], got: %v", args)
+	}
+
+	// WithEqualsMap consumes the OR, so it doesn't leak onto the next filter.
+	query, _ = mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("name", OpEq, "bob"),
+		WithOr(),
+		WithEqualsMap(map[string]interface{}{"email": "bob@example.com"}),
+		WithFilter("age", OpGt, 65),
+	})
+	expectedQuery = "SELECT * FROM users WHERE (users.name = $1 OR users.email = $2) AND users.age > $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	_, err = NewPaginator(WithTable("users"), WithStruct(User{}), WithFilter("name", OpEq, "bob"), WithOr())
+	if err == nil || !strings.Contains(err.Error(), "WithOr must be followed by a filter") {
+		t.Errorf("Expected dangling WithOr error, got: %v", err)
+	}
+}
+
+// TestWithFieldAnyOf tests a single-field group ORing different operators.
+func TestWithFieldAnyOf(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("name", OpEq, "john"),
+		WithFieldAnyOf("age", Is(OpGte, 100), Is(OpIsNull), Is(OpBetween, 1, 5)),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.name = $1 AND (users.age >= $2 OR users.age IS NULL OR users.age BETWEEN $3 AND $4) LIMIT $5 OFFSET $6"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", 100, 1, 5, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithValidateRanges tests rejecting reversed ranges.
+func TestWithValidateRanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     []Option
+		expectError bool
+	}{
+		{"valid between", []Option{WithFilter("age", OpBetween, 18, 65)}, false},
+		{"reversed between", []Option{WithFilter("age", OpBetween, 65, 18)}, true},
+		{"reversed string between", []Option{WithFilter("age", OpBetween, "65", "9")}, true},
+		{"reversed dates", []Option{WithFilter("age", OpNotBetween, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))}, true},
+		{"valid bounds", []Option{WithFilter("age", OpGte, 18), WithFilter("age", OpLte, 65)}, false},
+		{"reversed bounds", []Option{WithFilter("age", OpGte, 65), WithFilter("age", OpLte, 18)}, true},
+		{"bounds on different fields", []Option{WithFilter("age", OpGte, 65), WithFilter("id", OpLte, 18)}, false},
+		{"bounds in an OR group", []Option{WithFieldAnyOf("age", Is(OpGt, 65), Is(OpLt, 18))}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithTable("users"), WithStruct(User{}), WithValidateRanges()}, tt.options...)
+			_, err := NewPaginator(options...)
+			if tt.expectError && err == nil {
+				t.Error("Expected error, got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithFilter("age", OpBetween, 65, 18)); err != nil {
+		t.Errorf("Expected reversed ranges to be allowed without validation, got: %v", err)
+	}
+}
+
+// TestWithFilterStruct tests filters from a struct with operator tags.
+func TestWithFilterStruct(t *testing.T) {
+	type UserFilter struct {
+		MinAge int     `filter:"gte,age"`
+		Email  *string `filter:"eq,email"`
+		Name   string  `filter:"like,name"`
+		IDs    []int   `filter:"in,id"`
+		Page   int
+	}
+
+	email := ""
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilterStruct(UserFilter{MinAge: 18, Email: &email, Name: "jo", IDs: []int{1, 2}, Page: 2}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.age >= $1 AND users.email = $2 AND users.name::TEXT ILIKE $3 AND users.id IN ($4, $5) LIMIT $6 OFFSET $7"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "", "%jo%", 1, 2, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	type InvalidFilter struct {
+		Age int `filter:"gte"`
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithFilterStruct(InvalidFilter{Age: 1})); err == nil {
+		t.Error("Expected error for a filter tag without a field")
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithFilterStruct(42)); err == nil {
+		t.Error("Expected error for a non-struct filter")
+	}
+}
+
+// TestDescribeFilters tests the readable descriptions of the filters.
+func TestDescribeFilters(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("age", OpGte, 18),
+		WithFilter("name", OpIn, "John", "Jane"),
+		WithFilter("email", OpLike, "example.com"),
+		WithFilter("age", OpBetween, 18, 65),
+		WithFilter("email", OpIsNotNull),
+		WithFilter("id", OpNeq, 7),
+		WithFilter("id", OpNotIn, []int{1, 2}),
+		WithFilter("name", OpEq, []string{}),
+		WithFilter("age", OpBetween, []int{}, 65),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"age >= 18",
+		"name in (John, Jane)",
+		"email contains example.com",
+		"age between 18 and 65",
+		"email is not empty",
+		"id != 7",
+		"id not in (1, 2)",
+		"name eq ()",
+		"age between (65)",
+	}
+	if descriptions := p.DescribeFilters(); !reflect.DeepEqual(descriptions, expected) {
+		t.Errorf("Expected descriptions: %q\nGot: %q", expected, descriptions)
+	}
+}
+
+// TestWithFilterInI tests the case-insensitive IN filter.
+func TestWithFilterInI(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilterInI("name", "John", "JANE"),
+		WithFilter("age", OpGte, 18),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE LOWER(users.name) IN (LOWER($1), LOWER($2)) AND users.age >= $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"John", "JANE", 18, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	values, err := url.ParseQuery("ini[name]=Active,Pending")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, args = mustGenerateSQL(t, []Option{WithTable("users"), WithStruct(User{}), WithQuery(values)})
+	expectedQuery = "SELECT * FROM users WHERE LOWER(users.name) IN (LOWER($1), LOWER($2)) LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs = []interface{}{"Active", "Pending", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestFilterableFields tests documenting the tagged fields of a model.
+func TestFilterableFields(t *testing.T) {
+	type Account struct {
+		ID        int        `json:"id" paginate:"accounts.id"`
+		Email     string     `json:"email,omitempty" paginate:"accounts.email"`
+		DeletedAt *time.Time `json:"deleted_at" paginate:"accounts.deleted_at"`
+		Password  string     `json:"-" paginate:"accounts.password"`
+		Notes     string     `json:"notes"`
+		internal  string
+	}
+
+	expected := []FieldDoc{
+		{Name: "id", Column: "accounts.id", Type: "int"},
+		{Name: "email", Column: "accounts.email", Type: "string"},
+		{Name: "deleted_at", Column: "accounts.deleted_at", Type: "*time.Time"},
+	}
+	for _, model := range []interface{}{Account{}, &Account{}} {
+		if docs := FilterableFields(model); !reflect.DeepEqual(docs, expected) {
+			t.Errorf("Expected docs: %v\nGot: %v", expected, docs)
+		}
+	}
+	if docs := FilterableFields("accounts"); docs != nil {
+		t.Errorf("Expected no docs for a non-struct, got: %v", docs)
+	}
+}
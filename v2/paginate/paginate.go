@@ -1,32 +1,232 @@
 package paginate
 
 import (
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
 )
 
+// jsonPathSegment matches a single key of a JSON path.
+var jsonPathSegment = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// windowExpression matches a window function call like ROW_NUMBER() OVER (ORDER BY score DESC).
+var windowExpression = regexp.MustCompile(`(?i)^[A-Z_][A-Z0-9_]*\([A-Za-z0-9_., ]*\) OVER \([A-Za-z0-9_., ]*\)$`)
+
+// identifier matches an unquoted SQL identifier.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dollarPlaceholder matches a Postgres positional placeholder.
+var dollarPlaceholder = regexp.MustCompile(`\$[0-9]+`)
+
+// deterministic is set by SetDeterministic.
+var deterministic atomic.Bool
+
+// SetDeterministic makes every paginator created afterwards sort its filters,
+// search fields, search conditions and column comparisons, so the generated
+// SQL doesn't depend on the order options were given in, e.g. when they are
+// built from a map. Select columns and where clauses keep their order. It is
+// off by default and meant for snapshot tests.
+func SetDeterministic(enabled bool) {
+	deterministic.Store(enabled)
+}
+
 // QueryParams contains the parameters for the paginated query.
 type QueryParams struct {
-	Page           int
-	ItemsPerPage   int
-	Search         string
-	SearchFields   []string
-	Vacuum         bool
-	Columns        []string
-	Joins          []string
-	SortColumns    []string
-	SortDirections []string
-	WhereClauses   []string
-	WhereArgs      []interface{}
-	WhereCombining string
-	Schema         string
-	Table          string
-	Struct         interface{}
-	MapArgs        map[string]interface{}
-	NoOffset       bool
+	Page                  int
+	ItemsPerPage          int
+	Search                string
+	SearchFields          []string
+	Vacuum                bool
+	Columns               []string
+	Joins                 []string
+	SortColumns           []string
+	SortDirections        []string
+	WhereClauses          []string
+	WhereArgs             []interface{}
+	WhereCombining        string
+	Schema                string
+	Table                 string
+	Struct                interface{}
+	MapArgs               map[string]interface{}
+	NoOffset              bool
+	AutoColumns           bool
+	RejectUnknownFields   bool
+	MaxOffset             int64
+	MetricsHook           func(QueryMetrics)
+	NullsOrdering         NullsOrder
+	SearchConditions      []SearchCondition
+	MinimalParens         bool
+	Filters               []Filter
+	JSONSorts             []JSONSort
+	NullsSorts            []NullsSort
+	DefaultFilters        []Option
+	ColumnComparisons     []ColumnComparison
+	Dialect               Dialect
+	ExplainAnalyze        bool
+	Fields                []string
+	FieldGuard            func(field, op string) bool
+	MultiSearches         []MultiSearch
+	Coalesce              map[string]interface{}
+	QuoteTable            bool
+	GroupBy               []string
+	HavingClauses         []string
+	HavingArgs            []interface{}
+	HavingCombining       string
+	OrFilters             []Option
+	CaseInsensitiveFields bool
+	RowSecurity           []RowSecurity
+	SQLSideWildcards      bool
+	SearchCombining       string
+	InlineLimitOffset     bool
+	PadSortDirections     bool
+	MaxSortColumns        int
+	JoinSafePagination    bool
+	DataWhereClauses      []string
+	DataWhereArgs         []interface{}
+	CountWhereClauses     []string
+	CountWhereArgs        []interface{}
+	EstimatedCount        func(params *QueryParams) (string, []interface{})
+	Includes              []string
+	TableAlias            string
+	SearchRank            string
+	MaxSQLLength          int
+	CountColumn           string
+	SortPrecedence        SortPrecedence
+	DialectColumns        []DialectColumn
+	ValidateRanges        bool
+	WeightedSearch        *WeightedSearch
+	ImplicitInFields      []string
+	SoftDeleteColumn      string
+	RecencyColumn         string
+	JSONFiltersKey        string
+	SearchAll             bool
+	SearchExclude         []string
+	AnsiLimit             bool
+	ConditionalJoins      []ConditionalJoin
+	EstimateThreshold     int
+	RawSelect             string
+	RawSelectArgs         []interface{}
+	PartitionColumn       string
+	PartitionValue        interface{}
+	RedactLength          int
+	RedactFields          []string
+	DedupeRows            bool
+	SelectFlags           []SelectFlag
+	Cursor                *Cursor
+	AllowedColumns        []string
+	ValidateSearchFields  bool
+
+	// errs collects the errors of options parsing their input, returned by NewPaginator.
+	errs []error
+	// orNext makes the next filter join the previous filter with OR.
+	orNext bool
+	// idColumn memoizes the id column resolved by NewPaginator.
+	idColumn string
+	// placeholder overrides the dialect placeholders of the data query.
+	placeholder func(index int) string
+	// redacting makes the clause builders bind the redactedMarker for the
+	// RedactFields, to locate their arguments in GenerateWithRedaction.
+	redacting bool
+}
+
+// WeightedSearch is a full-text search term with the weight of each field.
+type WeightedSearch struct {
+	Term    string
+	Weights map[string]float64
+}
+
+// DialectColumn is a select column whose expression depends on the dialect.
+type DialectColumn struct {
+	Expressions map[Dialect]string
+	Alias       string
+}
+
+// SelectFlag is a computed select column `(expression) AS alias` and the
+// arguments of its placeholders.
+type SelectFlag struct {
+	Alias      string
+	Expression string
+	Args       []interface{}
+}
+
+// RowSecurity is a row-level security predicate and its arguments.
+type RowSecurity struct {
+	Clause string
+	Args   []interface{}
+}
+
+// NullsSort orders by a field with its own NULLS placement.
+type NullsSort struct {
+	Field     string
+	Direction string
+	Nulls     NullsOrder
+}
+
+// JSONSort orders by a key inside a JSON column.
+type JSONSort struct {
+	Field     string
+	Path      string
+	Direction string
+}
+
+// SearchMode defines how a search term is matched against a field.
+type SearchMode int
+
+const (
+	// SearchContains matches fields containing the term.
+	SearchContains SearchMode = iota
+	// SearchExact matches fields equal to the term.
+	SearchExact
+	// SearchPrefix matches fields starting with the term.
+	SearchPrefix
+	// SearchSuffix matches fields ending with the term.
+	SearchSuffix
+)
+
+// MultiSearch matches any of its terms in any of its fields.
+type MultiSearch struct {
+	Terms  []string
+	Fields []string
+}
+
+// SearchCondition is a search term applied to a single field.
+type SearchCondition struct {
+	Field string
+	Mode  SearchMode
+	Term  string
+}
+
+// NullsOrder controls where NULL values are placed in ORDER BY.
+type NullsOrder string
+
+const (
+	// NullsDefault keeps the database default placement.
+	NullsDefault NullsOrder = ""
+	// NullsFirst places NULL values first.
+	NullsFirst NullsOrder = "FIRST"
+	// NullsLast places NULL values last.
+	NullsLast NullsOrder = "LAST"
+)
+
+// QueryMetrics describes the shape of a generated query.
+type QueryMetrics struct {
+	Filters map[string]int
+	Joins   int
+	Sorted  bool
+	Limit   int
 }
 
 // Option is a function that configures options in QueryParams.
@@ -39,6 +239,118 @@ func WithNoOffset(noOffset bool) Option {
 	}
 }
 
+// WithInlineLimitOffset embeds LIMIT and OFFSET as integer literals instead of
+// placeholders, for drivers that can't bind them.
+func WithInlineLimitOffset() Option {
+	return func(params *QueryParams) {
+		params.InlineLimitOffset = true
+	}
+}
+
+// WithAutoColumns resolves columns for fields without a paginate tag using
+// the snake_cased struct field name.
+func WithAutoColumns() Option {
+	return func(params *QueryParams) {
+		params.AutoColumns = true
+	}
+}
+
+// WithCaseInsensitiveFields matches field names against the struct tags
+// case-insensitively, so "Name" and "NAME" resolve like "name".
+func WithCaseInsensitiveFields() Option {
+	return func(params *QueryParams) {
+		params.CaseInsensitiveFields = true
+	}
+}
+
+// WithRejectUnknownFields makes NewPaginator fail when a field referenced by
+// the select fields, search, sort, filters, OR filters, column comparisons or
+// cursor can't be resolved to a column, instead of silently dropping it.
+func WithRejectUnknownFields() Option {
+	return func(params *QueryParams) {
+		params.RejectUnknownFields = true
+	}
+}
+
+// WithAllowColumns restricts the fields referenced by the select fields,
+// search, weighted search, sort, filters, OR filters, column comparisons and
+// cursor to the listed ones, given as field names or resolved columns. Any other field makes
+// NewPaginator return an error instead of being dropped, so field names can
+// come from user input.
+func WithAllowColumns(columns ...string) Option {
+	return func(params *QueryParams) {
+		params.AllowedColumns = append(params.AllowedColumns, columns...)
+	}
+}
+
+// WithValidateSearchFields drops the search fields whose struct field doesn't
+// hold text, like numbers, arrays or JSON, which the text cast of the search
+// doesn't match usefully or at all.
+func WithValidateSearchFields() Option {
+	return func(params *QueryParams) {
+		params.ValidateSearchFields = true
+	}
+}
+
+// WithMaxOffset sets the MaxOffset option, the largest OFFSET ever emitted.
+func WithMaxOffset(maxOffset int64) Option {
+	return func(params *QueryParams) {
+		params.MaxOffset = maxOffset
+	}
+}
+
+// WithMetricsHook sets a callback invoked with the query shape on every GenerateSQL call.
+func WithMetricsHook(hook func(QueryMetrics)) Option {
+	return func(params *QueryParams) {
+		params.MetricsHook = hook
+	}
+}
+
+// WithNullsOrdering sets the NULLS placement applied to every ORDER BY column.
+// MySQL and SQL Server emulate it with a CASE flag sorted first. Values other
+// than NullsDefault, NullsFirst and NullsLast make NewPaginator return an
+// error.
+func WithNullsOrdering(nullsOrdering NullsOrder) Option {
+	return func(params *QueryParams) {
+		params.NullsOrdering = nullsOrdering
+	}
+}
+
+// WithOrderByNulls adds an ORDER BY on the field, after the WithSort columns,
+// in the direction, ASC or DESC, placing NULLs FIRST or LAST regardless of
+// NullsOrdering, e.g. `ORDER BY users.age ASC NULLS LAST`. Other values make
+// NewPaginator return an error.
+func WithOrderByNulls(field, direction string, nullsOrder NullsOrder) Option {
+	return func(params *QueryParams) {
+		params.NullsSorts = append(params.NullsSorts, NullsSort{Field: field, Direction: direction, Nulls: nullsOrder})
+	}
+}
+
+// WithMinimalParens skips the wrapping parentheses of condition groups holding a single condition.
+func WithMinimalParens() Option {
+	return func(params *QueryParams) {
+		params.MinimalParens = true
+	}
+}
+
+// WithExplain sets whether GenerateExplainSQL runs the query with ANALYZE.
+func WithExplain(analyze bool) Option {
+	return func(params *QueryParams) {
+		params.ExplainAnalyze = analyze
+	}
+}
+
+// WithFieldGuard sets a callback authorizing each field used to search, filter,
+// sort or select. The op is the filter operator, or "search", "compare",
+// "sort" or "select". Fields the guard rejects are dropped, and rejecting
+// every field of WithFields is an error rather than a select of every column.
+// The filters of WithDefaultFilters are set by the server and aren't guarded.
+func WithFieldGuard(guard func(field, op string) bool) Option {
+	return func(params *QueryParams) {
+		params.FieldGuard = guard
+	}
+}
+
 // WithMapArgs sets the MapArgs option.
 func WithMapArgs(mapArgs map[string]interface{}) Option {
 	return func(params *QueryParams) {
@@ -53,7 +365,8 @@ func WithStruct(s interface{}) Option {
 	}
 }
 
-// WithSchema sets the Schema option.
+// WithSchema sets the Schema option. A schema-qualified table like
+// "public.users" keeps its own schema instead.
 func WithSchema(schema string) Option {
 	return func(params *QueryParams) {
 		params.Schema = schema
@@ -67,6 +380,23 @@ func WithTable(table string) Option {
 	}
 }
 
+// WithTableAlias sets the alias of the main table, emitted as
+// `FROM schema.table AS alias`. Columns tagged with the table name aren't
+// rewritten, except the id column of the count and join-safe queries.
+func WithTableAlias(alias string) Option {
+	return func(params *QueryParams) {
+		params.TableAlias = alias
+	}
+}
+
+// WithQuotedTable quotes the schema and table names in the FROM clause, e.g.
+// "public"."users". A table alias like "users u" is kept unquoted.
+func WithQuotedTable() Option {
+	return func(params *QueryParams) {
+		params.QuoteTable = true
+	}
+}
+
 // WithPage sets the Page option.
 func WithPage(page int) Option {
 	return func(params *QueryParams) {
@@ -81,6 +411,53 @@ func WithItemsPerPage(itemsPerPage int) Option {
 	}
 }
 
+// WithPageString sets the Page option from a raw string, e.g. a query param.
+// An empty string keeps the default; anything but a positive integer makes
+// NewPaginator return an error.
+func WithPageString(page string) Option {
+	return func(params *QueryParams) {
+		params.setPositiveInt(&params.Page, "page", page)
+	}
+}
+
+// WithItemsPerPageString sets the ItemsPerPage option from a raw string. An
+// empty string keeps the default; anything but a positive integer makes
+// NewPaginator return an error.
+func WithItemsPerPageString(itemsPerPage string) Option {
+	return func(params *QueryParams) {
+		params.setPositiveInt(&params.ItemsPerPage, "items per page", itemsPerPage)
+	}
+}
+
+// setPositiveInt parses the non-empty value into target, recording an error
+// when it isn't a positive integer.
+func (params *QueryParams) setPositiveInt(target *int, name, value string) {
+	if value == "" {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		params.errs = append(params.errs, fmt.Errorf("invalid %s %q: must be a positive integer", name, value))
+		return
+	}
+	*target = n
+}
+
+// WithInclude adds relations to eager-load, for execution adapters like ORM
+// preloads; the SQL generator ignores them. Each relation may also be a
+// comma-separated list such as the `include` query param.
+func WithInclude(relations ...string) Option {
+	return func(params *QueryParams) {
+		for _, relation := range relations {
+			for _, name := range strings.Split(relation, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					params.Includes = append(params.Includes, name)
+				}
+			}
+		}
+	}
+}
+
 // WithSearch sets the Search option.
 func WithSearch(search string) Option {
 	return func(params *QueryParams) {
@@ -88,6 +465,45 @@ func WithSearch(search string) Option {
 	}
 }
 
+// WithSearchRank selects the full-text relevance of the search term in the
+// search fields as the alias column, and orders by it descending when no other
+// sort is set. It requires the postgres dialect.
+func WithSearchRank(alias string) Option {
+	return func(params *QueryParams) {
+		params.SearchRank = alias
+	}
+}
+
+// WithSearchWeighted adds a Postgres full-text search of the term in the
+// weighted fields, selecting its ts_rank as the rank column (named by
+// WithSearchRank) and ordering by it unless another sort is set. Weights map to
+// the setweight labels A (>= 1), B (>= 0.4), C (>= 0.2) and D.
+func WithSearchWeighted(term string, weights map[string]float64) Option {
+	return func(params *QueryParams) {
+		params.WeightedSearch = &WeightedSearch{Term: term, Weights: weights}
+	}
+}
+
+// WithRawSelect replaces the whole select list, including the rank column,
+// with the SQL verbatim, e.g. DISTINCT ON or CASE expressions. Its arguments
+// come before all the others.
+func WithRawSelect(selectSQL string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.RawSelect = selectSQL
+		params.RawSelectArgs = args
+	}
+}
+
+// WithSearchAllExcept searches the term in every tagged string field of the
+// struct except the excluded ones, e.g. sensitive notes.
+func WithSearchAllExcept(term string, exclude ...string) Option {
+	return func(params *QueryParams) {
+		params.Search = term
+		params.SearchAll = true
+		params.SearchExclude = append(params.SearchExclude, exclude...)
+	}
+}
+
 // WithSearchFields sets the SearchFields option.
 func WithSearchFields(searchFields []string) Option {
 	return func(params *QueryParams) {
@@ -95,6 +511,46 @@ func WithSearchFields(searchFields []string) Option {
 	}
 }
 
+// WithSearchField adds a search condition matching a single field with its own mode and term.
+func WithSearchField(field string, mode SearchMode, term string) Option {
+	return func(params *QueryParams) {
+		params.SearchConditions = append(params.SearchConditions, SearchCondition{Field: field, Mode: mode, Term: term})
+	}
+}
+
+// WithSearchConditionsCombining sets the operator joining the WithSearchField
+// conditions: "OR" (default) or "AND".
+func WithSearchConditionsCombining(combining string) Option {
+	return func(params *QueryParams) {
+		params.SearchCombining = combining
+	}
+}
+
+// WithSearchAnyOf adds a group matching any of the terms in any of the fields.
+func WithSearchAnyOf(terms []string, fields ...string) Option {
+	return func(params *QueryParams) {
+		params.MultiSearches = append(params.MultiSearches, MultiSearch{Terms: terms, Fields: fields})
+	}
+}
+
+// WithSQLSideWildcards passes search terms as raw arguments and concatenates
+// the % wildcards in SQL, e.g. col::TEXT ILIKE '%' || $1 || '%'.
+func WithSQLSideWildcards() Option {
+	return func(params *QueryParams) {
+		params.SQLSideWildcards = true
+	}
+}
+
+// WithEstimateThreshold makes the vacuum count query return the exact count
+// when the estimate is below the threshold, where estimates are least accurate.
+// It only applies to the Postgres count_estimate query, not to the estimate of
+// WithEstimatedCount or of the MySQL dialect.
+func WithEstimateThreshold(threshold int) Option {
+	return func(params *QueryParams) {
+		params.EstimateThreshold = threshold
+	}
+}
+
 // WithVacuum sets the Vacuum option.
 func WithVacuum(vacuum bool) Option {
 	return func(params *QueryParams) {
@@ -109,6 +565,59 @@ func WithColumn(column string) Option {
 	}
 }
 
+// WithSelectWindow adds a window function column like
+// `ROW_NUMBER() OVER (ORDER BY score DESC) AS rank` to the SELECT list. The
+// expression isn't resolved through the struct tags, so it is restricted to a
+// plain function call with an OVER clause; anything else makes NewPaginator
+// return an error.
+func WithSelectWindow(expression, alias string) Option {
+	return func(params *QueryParams) {
+		if !windowExpression.MatchString(expression) || !identifier.MatchString(alias) {
+			params.errs = append(params.errs, fmt.Errorf("invalid window column %q AS %q", expression, alias))
+			return
+		}
+		params.Columns = append(params.Columns, expression+" AS "+alias)
+	}
+}
+
+// WithSelectFlag adds a computed column like `(stock > ?) AS in_stock` to the
+// SELECT list, after `*` when no other column is selected. Its arguments come
+// before the WHERE arguments. An alias that isn't a plain identifier makes
+// NewPaginator return an error.
+func WithSelectFlag(alias, expression string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		if !identifier.MatchString(alias) || expression == "" {
+			params.errs = append(params.errs, fmt.Errorf("invalid select flag %q AS %q", expression, alias))
+			return
+		}
+		params.SelectFlags = append(params.SelectFlags, SelectFlag{Alias: alias, Expression: expression, Args: args})
+	}
+}
+
+// WithSelectDialect adds a select column using the expression of the active
+// dialect, e.g. {DialectPostgres: "first || ' ' || last", DialectMySQL:
+// "CONCAT(first, ' ', last)"}. A dialect without an expression makes
+// NewPaginator return an error.
+func WithSelectDialect(expressions map[Dialect]string, alias string) Option {
+	return func(params *QueryParams) {
+		params.DialectColumns = append(params.DialectColumns, DialectColumn{Expressions: expressions, Alias: alias})
+	}
+}
+
+// WithFields sets the Fields option from a comma-separated list of json field
+// names, like the JSON:API `fields` query param. The fields are resolved to
+// their columns and added to the SELECT clause.
+func WithFields(fields string) Option {
+	return func(params *QueryParams) {
+		params.Fields = nil
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				params.Fields = append(params.Fields, field)
+			}
+		}
+	}
+}
+
 // WithSort sets the SortColumns and SortDirections options.
 func WithSort(sortColumns, sortDirections []string) Option {
 	return func(params *QueryParams) {
@@ -117,212 +626,1486 @@ func WithSort(sortColumns, sortDirections []string) Option {
 	}
 }
 
-// WithJoin adds a join clause to the Joins option.
-func WithJoin(join string) Option {
+// WithOrderBy sets the sort from a compact order_by string like
+// "name:asc,created_at:desc". The direction is optional and defaults to asc;
+// malformed pairs make NewPaginator return an error.
+func WithOrderBy(orderBy string) Option {
 	return func(params *QueryParams) {
-		params.Joins = append(params.Joins, join)
+		var columns, directions []string
+		for _, pair := range strings.Split(orderBy, ",") {
+			column, direction, _ := strings.Cut(strings.TrimSpace(pair), ":")
+			column = strings.TrimSpace(column)
+			var descending bool
+			switch strings.ToLower(strings.TrimSpace(direction)) {
+			case "", "asc":
+			case "desc":
+				descending = true
+			default:
+				params.errs = append(params.errs, fmt.Errorf("invalid order_by direction in %q", pair))
+				return
+			}
+			if column == "" {
+				params.errs = append(params.errs, fmt.Errorf("invalid order_by pair %q", pair))
+				return
+			}
+			columns = append(columns, column)
+			directions = append(directions, strconv.FormatBool(descending))
+		}
+		params.SortColumns = columns
+		params.SortDirections = directions
 	}
 }
 
-// WithWhereCombining sets the WhereCombining option.
-func WithWhereCombining(combining string) Option {
+// WithCountColumn sets the column counted by the count query instead of the id
+// field. It makes the struct optional, for count and aggregate queries that
+// use only the table, custom columns and where clauses.
+func WithCountColumn(column string) Option {
 	return func(params *QueryParams) {
-		params.WhereCombining = combining
+		params.CountColumn = column
 	}
 }
 
-// WithWhereClause adds a where clause and its arguments.
-func WithWhereClause(clause string, args ...interface{}) Option {
+// WithMaxSQLLength makes NewPaginator return an error when the generated query
+// is longer than n characters, e.g. because a client sent hundreds of filter values.
+func WithMaxSQLLength(n int) Option {
 	return func(params *QueryParams) {
-		params.WhereClauses = append(params.WhereClauses, clause)
-		params.WhereArgs = append(params.WhereArgs, args...)
+		params.MaxSQLLength = n
 	}
 }
 
-// NewPaginator creates a new QueryParams instance with the given options.
-func NewPaginator(options ...Option) (*QueryParams, error) {
-	params := &QueryParams{
-		Page:           1,
-		ItemsPerPage:   10,
-		WhereCombining: "AND",
-		NoOffset:       false,
+// WithValidateRanges makes NewPaginator return an error for reversed ranges,
+// like a between filter from 65 to 18 or gte 65 with lte 18 on the same field,
+// which would silently match nothing.
+func WithValidateRanges() Option {
+	return func(params *QueryParams) {
+		params.ValidateRanges = true
 	}
+}
 
-	// Apply options
-	for _, option := range options {
-		option(params)
+// WithMaxSortColumns caps the number of sort columns, 5 by default; more make
+// NewPaginator return an error. Zero disables the cap.
+func WithMaxSortColumns(n int) Option {
+	return func(params *QueryParams) {
+		params.MaxSortColumns = n
 	}
+}
 
-	// Validation
-	if params.Table == "" {
-		return nil, errors.New("principal table is required")
+// WithPadSortDirections sorts columns without a matching direction ASC instead
+// of dropping the whole ORDER BY when the lengths mismatch.
+func WithPadSortDirections() Option {
+	return func(params *QueryParams) {
+		params.PadSortDirections = true
 	}
+}
 
-	if params.Struct == nil {
-		return nil, errors.New("struct is required")
+// WithSortJSON adds an ORDER BY on a key of a JSON field. The path uses dots
+// for nested keys, e.g. "address.city", and the direction is ASC or DESC.
+func WithSortJSON(field, path, direction string) Option {
+	return func(params *QueryParams) {
+		params.JSONSorts = append(params.JSONSorts, JSONSort{Field: field, Path: path, Direction: direction})
 	}
+}
 
-	return params, nil
+// WithJoin adds a join clause to the Joins option.
+func WithJoin(join string) Option {
+	return func(params *QueryParams) {
+		params.Joins = append(params.Joins, join)
+	}
 }
 
-// GenerateSQL generates the paginated SQL query and its arguments.
-func (params *QueryParams) GenerateSQL() (string, []interface{}) {
-	var clauses []string
-	var args []interface{}
+// ConditionalJoin is a LEFT JOIN emitted only when the query references the
+// alias of its table, the last word of Table, e.g. "profiles p".
+type ConditionalJoin struct {
+	Table     string
+	Condition string
+}
 
-	// SELECT clause
-	selectClause := "SELECT "
-	if len(params.Columns) > 0 {
-		selectClause += strings.Join(params.Columns, ", ")
-	} else {
-		selectClause += "*"
+// WithJoinIfUsed adds a LEFT JOIN of the table on the condition, emitted only
+// when a select column, filter, group or sort references the table alias, e.g.
+// WithJoinIfUsed("profiles p", "p.user_id = users.id") joins for p.bio.
+func WithJoinIfUsed(table, condition string) Option {
+	return func(params *QueryParams) {
+		params.ConditionalJoins = append(params.ConditionalJoins, ConditionalJoin{Table: table, Condition: condition})
 	}
-	clauses = append(clauses, selectClause)
+}
 
-	// FROM clause
-	fromClause := fmt.Sprintf("FROM %s", params.Table)
-	if params.Schema != "" {
-		fromClause = fmt.Sprintf("FROM %s.%s", params.Schema, params.Table)
+// WithJoinSafePagination paginates the ids of the base table in a subquery
+// before joining, so one-to-many joins don't reduce the number of parent rows
+// per page. Where and sort clauses must then reference the base table.
+func WithJoinSafePagination() Option {
+	return func(params *QueryParams) {
+		params.JoinSafePagination = true
 	}
-	clauses = append(clauses, fromClause)
+}
 
-	// JOIN clauses
-	if len(params.Joins) > 0 {
-		clauses = append(clauses, strings.Join(params.Joins, " "))
+// WithWhereCombining sets the WhereCombining option.
+func WithWhereCombining(combining string) Option {
+	return func(params *QueryParams) {
+		params.WhereCombining = combining
 	}
+}
 
-	// WHERE clause
-	whereClauses, whereArgs := params.buildWhereClauses()
-	if len(whereClauses) > 0 {
-		clauses = append(clauses, "WHERE "+strings.Join(whereClauses, " AND "))
-		args = append(args, whereArgs...)
+// WithDefaultFilters sets filters that are always applied. Only the filters and
+// where clauses set by the options are used, and they are kept apart from the
+// request filters so they can't be overridden.
+func WithDefaultFilters(options ...Option) Option {
+	return func(params *QueryParams) {
+		params.DefaultFilters = append(params.DefaultFilters, options...)
 	}
+}
 
-	// ORDER BY clause
-	orderClause := params.buildOrderClause()
-	if orderClause != "" {
-		clauses = append(clauses, orderClause)
+// WithOrFilter adds filters ORed with the search group, e.g. to also list
+// featured rows that don't match the search. Only the filters and where
+// clauses set by the options are used, and they are ignored without a search.
+func WithOrFilter(options ...Option) Option {
+	return func(params *QueryParams) {
+		params.OrFilters = append(params.OrFilters, options...)
 	}
-
-	// LIMIT and OFFSET
-	limitOffsetClause, limitOffsetArgs := params.buildLimitOffsetClause()
-	clauses = append(clauses, limitOffsetClause)
-	args = append(args, limitOffsetArgs...)
-
-	// Combine all clauses
-	query := strings.Join(clauses, " ")
-
-	// Replace placeholders
-	query, args = replacePlaceholders(query, args)
-	return query, args
 }
 
-// GenerateCountQuery generates the SQL query for counting total records.
-func (params *QueryParams) GenerateCountQuery() (string, []interface{}) {
-	var clauses []string
-	var args []interface{}
-
-	// SELECT COUNT clause
-	countSelectClause := "SELECT COUNT(id)"
-	idColumnName := getFieldName("id", "json", "paginate", params.Struct)
-	if idColumnName != "" {
-		countSelectClause = fmt.Sprintf("SELECT COUNT(%s)", idColumnName)
+// WithRowSecurity adds a row-level security predicate, e.g.
+// "owner_id = ? OR is_public". Predicates are wrapped in parentheses and always
+// ANDed with the rest of the WHERE clause, regardless of WhereCombining.
+func WithRowSecurity(clause string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.RowSecurity = append(params.RowSecurity, RowSecurity{Clause: clause, Args: args})
 	}
-	clauses = append(clauses, countSelectClause)
+}
 
-	// FROM clause
-	fromClause := fmt.Sprintf("FROM %s", params.Table)
-	if params.Schema != "" {
-		fromClause = fmt.Sprintf("FROM %s.%s", params.Schema, params.Table)
+// WithRecencyTiebreak appends "column DESC" after the requested sorts, e.g.
+// "users.created_at", so rows equal on the primary sort show newest first.
+func WithRecencyTiebreak(column string) Option {
+	return func(params *QueryParams) {
+		params.RecencyColumn = column
 	}
-	clauses = append(clauses, fromClause)
+}
 
-	// JOIN clauses
-	if len(params.Joins) > 0 {
-		clauses = append(clauses, strings.Join(params.Joins, " "))
+// WithAnsiLimit emits the ANSI-standard "OFFSET n ROWS FETCH FIRST n ROWS ONLY"
+// instead of LIMIT and OFFSET. It requires a sort, since engines like SQL
+// Server reject OFFSET without ORDER BY, and isn't supported on MySQL or SQLite,
+// which only accept LIMIT.
+func WithAnsiLimit() Option {
+	return func(params *QueryParams) {
+		params.AnsiLimit = true
+	}
+}
+
+// WithPartitionFilter constrains the partition key column of a partitioned
+// table to the value, first in the WHERE clause of the data and count queries,
+// so the planner prunes the other partitions.
+func WithPartitionFilter(column string, value interface{}) Option {
+	return func(params *QueryParams) {
+		params.PartitionColumn = column
+		params.PartitionValue = value
+	}
+}
+
+// WithRedaction sets the arguments redacted by GenerateWithRedaction: strings
+// longer than maxLength, when positive, and the values bound to the sensitive
+// fields by any search, filter, default or OR filter and cursor. A term shared
+// with a sensitive field, like the weighted search term, is redacted.
+func WithRedaction(maxLength int, fields ...string) Option {
+	return func(params *QueryParams) {
+		params.RedactLength = maxLength
+		params.RedactFields = append(params.RedactFields, fields...)
+	}
+}
+
+// WithDedupeRows makes StreamRows skip the rows repeating the id of the
+// previous row, keeping the first, e.g. parent rows fanned out by joins. It is
+// a stopgap: prefer DISTINCT or WithJoinSafePagination in the SQL.
+func WithDedupeRows() Option {
+	return func(params *QueryParams) {
+		params.DedupeRows = true
+	}
+}
+
+// WithSoftDelete excludes the soft-deleted rows, whose column is not NULL, e.g.
+// "users.deleted_at", from both the data and the count queries.
+func WithSoftDelete(column string) Option {
+	return func(params *QueryParams) {
+		params.SoftDeleteColumn = column
+	}
+}
+
+// WithWhereClause adds a where clause and its arguments.
+func WithWhereClause(clause string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.WhereClauses = append(params.WhereClauses, clause)
+		params.WhereArgs = append(params.WhereArgs, args...)
+	}
+}
+
+// WithDataOnlyWhere adds a WHERE clause applied only to the paginated query,
+// not to the count query.
+func WithDataOnlyWhere(clause string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.DataWhereClauses = append(params.DataWhereClauses, clause)
+		params.DataWhereArgs = append(params.DataWhereArgs, args...)
+	}
+}
+
+// WithCountOnlyWhere adds a WHERE clause applied only to the count query, e.g.
+// to exclude a pinned row from the total.
+func WithCountOnlyWhere(clause string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.CountWhereClauses = append(params.CountWhereClauses, clause)
+		params.CountWhereArgs = append(params.CountWhereArgs, args...)
+	}
+}
+
+// WithGroupBy adds columns to the GROUP BY clause.
+func WithGroupBy(columns ...string) Option {
+	return func(params *QueryParams) {
+		params.GroupBy = append(params.GroupBy, columns...)
+	}
+}
+
+// WithGroupByRollup adds ROLLUP(columns) to the GROUP BY clause, grouping by
+// each prefix of the columns for subtotals.
+func WithGroupByRollup(columns ...string) Option {
+	return WithGroupBy("ROLLUP(" + strings.Join(columns, ", ") + ")")
+}
+
+// WithGroupByCube adds CUBE(columns) to the GROUP BY clause, grouping by every
+// combination of the columns.
+func WithGroupByCube(columns ...string) Option {
+	return WithGroupBy("CUBE(" + strings.Join(columns, ", ") + ")")
+}
+
+// WithHavingCombining sets the HavingCombining option.
+func WithHavingCombining(combining string) Option {
+	return func(params *QueryParams) {
+		params.HavingCombining = combining
+	}
+}
+
+// WithHaving adds a having clause and its arguments.
+func WithHaving(clause string, args ...interface{}) Option {
+	return func(params *QueryParams) {
+		params.HavingClauses = append(params.HavingClauses, clause)
+		params.HavingArgs = append(params.HavingArgs, args...)
+	}
+}
+
+// NewPaginator creates a new QueryParams instance with the given options.
+func NewPaginator(options ...Option) (*QueryParams, error) {
+	params := &QueryParams{
+		Page:            1,
+		ItemsPerPage:    10,
+		WhereCombining:  "AND",
+		HavingCombining: "AND",
+		SearchCombining: "OR",
+		MaxSortColumns:  5,
+		NoOffset:        false,
+		Dialect:         DialectPostgres,
+	}
+
+	// Apply options
+	for _, option := range options {
+		option(params)
+	}
+	if err := params.prepare(); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// prepare resolves the derived state of the applied options and validates
+// them.
+func (params *QueryParams) prepare() error {
+	if params.Struct != nil {
+		if _, err := structType(params.Struct); err != nil {
+			return err
+		}
+	}
+	if params.SearchAll && params.Struct != nil {
+		for _, field := range textFields(params.Struct, params.SearchExclude) {
+			if !slices.Contains(params.SearchFields, field) {
+				params.SearchFields = append(params.SearchFields, field)
+			}
+		}
+	}
+	if params.ValidateSearchFields && params.Struct != nil {
+		params.dropNonTextSearchFields()
+	}
+	if deterministic.Load() {
+		params.sortConditions()
+	}
+
+	// Validation
+	if err := errors.Join(params.errs...); err != nil {
+		return err
+	}
+
+	if params.orNext {
+		return errors.New("WithOr must be followed by a filter")
+	}
+
+	if params.Table == "" {
+		return errors.New("principal table is required")
+	}
+
+	if params.AnsiLimit && params.buildOrderClause() == "" {
+		return errors.New("ANSI limit requires a sort")
+	}
+	if params.AnsiLimit && (params.Dialect == DialectMySQL || params.Dialect == DialectSQLite) {
+		return fmt.Errorf("ANSI limit isn't supported by the %s dialect", params.Dialect)
+	}
+
+	if params.SearchAll && params.Struct == nil {
+		return errors.New("searching all fields requires a struct")
+	}
+
+	if params.WeightedSearch != nil && params.Dialect != DialectPostgres {
+		return fmt.Errorf("weighted search requires the postgres dialect, got %s", params.Dialect)
+	}
+
+	if params.SearchRank != "" && params.Dialect != DialectPostgres {
+		return fmt.Errorf("search rank requires the postgres dialect, got %s", params.Dialect)
+	}
+
+	for _, column := range params.DialectColumns {
+		if !identifier.MatchString(column.Alias) {
+			return fmt.Errorf("invalid column alias %q", column.Alias)
+		}
+		if column.Expressions[params.Dialect] == "" {
+			return fmt.Errorf("no %s expression for column %q", params.Dialect, column.Alias)
+		}
+	}
+
+	if params.SearchRank != "" && !identifier.MatchString(params.SearchRank) {
+		return fmt.Errorf("invalid search rank alias %q", params.SearchRank)
+	}
+
+	if params.TableAlias != "" && !identifier.MatchString(params.TableAlias) {
+		return fmt.Errorf("invalid table alias %q", params.TableAlias)
+	}
+
+	if params.Struct == nil && params.CountColumn == "" {
+		return errors.New("struct is required")
+	}
+
+	if !strings.EqualFold(params.SearchCombining, "AND") && !strings.EqualFold(params.SearchCombining, "OR") {
+		return fmt.Errorf("invalid search combining operator %q", params.SearchCombining)
+	}
+
+	for _, filter := range params.Filters {
+		if err := filter.validate(); err != nil {
+			return err
+		}
+	}
+
+	if sorts := len(params.SortColumns) + len(params.JSONSorts) + len(params.NullsSorts); params.MaxSortColumns > 0 && sorts > params.MaxSortColumns {
+		return fmt.Errorf("too many sort columns: %d exceeds the maximum of %d", sorts, params.MaxSortColumns)
+	}
+
+	if params.ValidateRanges {
+		if err := validateRanges(params.Filters); err != nil {
+			return err
+		}
+	}
+
+	for _, jsonSort := range params.JSONSorts {
+		if err := jsonSort.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, nullsSort := range params.NullsSorts {
+		if err := nullsSort.validate(); err != nil {
+			return err
+		}
+	}
+
+	switch params.NullsOrdering {
+	case NullsDefault, NullsFirst, NullsLast:
+	default:
+		return fmt.Errorf("invalid nulls ordering %q, expected FIRST or LAST", params.NullsOrdering)
+	}
+
+	for _, comparison := range params.ColumnComparisons {
+		if err := comparison.validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, field := range params.Fields {
+		if params.columnName(field) == "" {
+			return fmt.Errorf("unknown field %q in fields", field)
+		}
+	}
+	if len(params.Fields) > 0 && !slices.ContainsFunc(params.Fields, func(field string) bool {
+		return params.guardedColumnName(field, "select") != ""
+	}) {
+		return errors.New("every field in fields is rejected by the field guard")
+	}
+
+	if params.Cursor != nil {
+		if err := params.Cursor.validate(); err != nil {
+			return err
+		}
+		for _, field := range params.Cursor.Fields {
+			if params.columnName(field) == "" {
+				return fmt.Errorf("unknown field %q in cursor", field)
+			}
+		}
+	}
+
+	if (params.Dialect == DialectOracle || params.Dialect == DialectSQLServer) && params.buildOrderClause() == "" {
+		return fmt.Errorf("%s dialect requires a sort", params.Dialect)
+	}
+
+	if params.RejectUnknownFields {
+		for _, field := range params.referencedFields() {
+			if params.columnName(field) == "" {
+				return fmt.Errorf("unknown field %q", field)
+			}
+		}
+	}
+
+	if len(params.AllowedColumns) > 0 {
+		for _, field := range params.referencedFields() {
+			if !slices.Contains(params.AllowedColumns, field) && !slices.Contains(params.AllowedColumns, params.columnName(field)) {
+				return fmt.Errorf("field %q is not allowed", field)
+			}
+		}
+	}
+
+	params.idColumn = params.resolveIDColumn()
+
+	if params.MaxSQLLength > 0 {
+		if query, _ := params.generateUnreported(); len(query) > params.MaxSQLLength {
+			return fmt.Errorf("generated query of %d characters exceeds the maximum of %d", len(query), params.MaxSQLLength)
+		}
+	}
+
+	return nil
+}
+
+// Apply applies the options to the params with the same validation as
+// NewPaginator, e.g. to add the filters of the next request after
+// ResetFilters. On error the params are left unchanged.
+func (params *QueryParams) Apply(options ...Option) error {
+	next := *params
+	for _, option := range options {
+		option(&next)
+	}
+	if err := next.prepare(); err != nil {
+		return err
+	}
+	*params = next
+	return nil
+}
+
+// ResetFilters clears the request state, i.e. the filters, the where, data,
+// count and having clauses, the search, the sort, the fields, the includes and
+// the pagination, so the params can be reused for another request with Apply.
+// The table, model, schema, joins, dialect and the other configuration like
+// default filters and row-level security are kept.
+func (params *QueryParams) ResetFilters() {
+	params.Page, params.ItemsPerPage = 1, 10
+	params.Search, params.SearchFields = "", nil
+	params.SearchConditions, params.MultiSearches, params.WeightedSearch = nil, nil, nil
+	params.SearchAll, params.SearchExclude = false, nil
+	params.SortColumns, params.SortDirections, params.JSONSorts, params.NullsSorts = nil, nil, nil, nil
+	params.Filters, params.OrFilters, params.ColumnComparisons = nil, nil, nil
+	params.WhereClauses, params.WhereArgs = nil, nil
+	params.DataWhereClauses, params.DataWhereArgs = nil, nil
+	params.CountWhereClauses, params.CountWhereArgs = nil, nil
+	params.HavingClauses, params.HavingArgs = nil, nil
+	params.Fields, params.Includes = nil, nil
+	params.Cursor = nil
+	params.errs, params.orNext = nil, false
+}
+
+// GenerateSQL generates the paginated SQL query and its arguments.
+func (params *QueryParams) GenerateSQL() (string, []interface{}) {
+	whereClauses, whereArgs := params.buildWhereClauses()
+	return params.generateSQL(whereClauses, whereArgs, nil)
+}
+
+// generateUnreported generates the paginated SQL query without reporting
+// metrics, for the queries that aren't run as such.
+func (params *QueryParams) generateUnreported() (string, []interface{}) {
+	unreported := *params
+	unreported.MetricsHook = nil
+	return unreported.GenerateSQL()
+}
+
+// sqlBuffers holds the slices reused across the generations of GenerateSQLPooled.
+type sqlBuffers struct {
+	whereClauses     []string
+	whereArgs        []interface{}
+	searchConditions []string
+	filterGroups     [][]string
+	filterClauses    []string
+	filterArgs       []interface{}
+	clauses          []string
+	args             []interface{}
+	finalArgs        []interface{}
+}
+
+// reset clears the buffers so the pool doesn't retain the arguments.
+func (buffers *sqlBuffers) reset() {
+	clear(buffers.whereClauses)
+	clear(buffers.whereArgs)
+	clear(buffers.searchConditions)
+	for _, group := range buffers.filterGroups {
+		clear(group)
+	}
+	clear(buffers.filterClauses)
+	clear(buffers.filterArgs)
+	clear(buffers.clauses)
+	clear(buffers.args)
+	clear(buffers.finalArgs)
+}
+
+// sqlBufferPool pools the sqlBuffers of GenerateSQLPooled.
+var sqlBufferPool = sync.Pool{New: func() interface{} { return &sqlBuffers{} }}
+
+// GenerateSQLPooled generates the paginated SQL query like GenerateSQL, reusing
+// pooled slices for the WHERE, filter and clause lists and for the arguments,
+// for services regenerating the same query shape at high throughput. The
+// arguments must not be used after calling release, which returns the slices
+// to the pool.
+func (params *QueryParams) GenerateSQLPooled() (query string, args []interface{}, release func()) {
+	buffers := sqlBufferPool.Get().(*sqlBuffers)
+	whereClauses, whereArgs := params.whereClausesIn(buffers)
+	query, args = params.generateSQL(whereClauses, whereArgs, buffers)
+	return query, args, func() {
+		buffers.reset()
+		sqlBufferPool.Put(buffers)
+	}
+}
+
+// GenerateAll generates the paginated SQL query and the count query with their
+// arguments, building the WHERE clause once so both queries share it.
+func (params *QueryParams) GenerateAll() (string, []interface{}, string, []interface{}) {
+	whereClauses, whereArgs := params.buildWhereClauses()
+	query, args := params.generateSQL(whereClauses, whereArgs, nil)
+	countQuery, countArgs := params.generateCountQuery(whereClauses, whereArgs)
+	return query, args, countQuery, countArgs
+}
+
+// redactedMarker stands in for the sensitive values while locating their arguments.
+const redactedMarker = "\x00paginate:redacted\x00"
+
+// GenerateWithRedaction generates the paginated SQL query like GenerateSQL,
+// with a copy of the arguments safe for logs and telemetry spans, where the
+// arguments set by WithRedaction are replaced with "***".
+func (params *QueryParams) GenerateWithRedaction() (query string, args []interface{}, redactedArgs []interface{}) {
+	query, args = params.GenerateSQL()
+
+	// Generate again with the values bound to the sensitive fields marked to
+	// locate their arguments.
+	marked := *params
+	marked.MetricsHook = nil
+	marked.redacting = true
+	_, markedArgs := marked.GenerateSQL()
+
+	redactedArgs = make([]interface{}, len(args))
+	for i, arg := range args {
+		text, isString := arg.(string)
+		switch {
+		case i < len(markedArgs) && strings.Contains(fmt.Sprint(markedArgs[i]), redactedMarker),
+			isString && params.RedactLength > 0 && len(text) > params.RedactLength:
+			redactedArgs[i] = "***"
+		default:
+			redactedArgs[i] = arg
+		}
+	}
+	return query, args, redactedArgs
+}
+
+// redactedTerm returns the redactedMarker instead of the term bound to the
+// fields when generating for GenerateWithRedaction and any field is redacted.
+func (params *QueryParams) redactedTerm(term string, fields ...string) string {
+	if params.redacting && slices.ContainsFunc(fields, func(field string) bool { return slices.Contains(params.RedactFields, field) }) {
+		return redactedMarker
+	}
+	return term
+}
+
+// redactedValues returns the values bound to the field, marked when
+// generating for GenerateWithRedaction and the field is redacted.
+func (params *QueryParams) redactedValues(field string, values []interface{}) []interface{} {
+	if params.redacting && slices.Contains(params.RedactFields, field) {
+		return markedValues(values)
+	}
+	return values
+}
+
+// markedValues returns the filter values with each value, or slice element,
+// replaced with the redactedMarker. Nil values are kept, since they change the
+// predicate, e.g. the open bound of a notbetween range.
+func markedValues(values []interface{}) []interface{} {
+	marked := make([]interface{}, len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+		marked[i] = redactedMarker
+		if items, ok := sliceArg(value); ok {
+			markers := make([]interface{}, len(items))
+			for j := range markers {
+				markers[j] = redactedMarker
+			}
+			marked[i] = markers
+		}
+	}
+	return marked
+}
+
+// GenerateNamedSQL generates the paginated SQL query with named placeholders,
+// :arg1, :arg2 and so on, and the arguments by name, for sqlx.NamedQuery.
+// Literal colons, like those of ::TEXT casts, are escaped as sqlx expects.
+func (params *QueryParams) GenerateNamedSQL() (string, map[string]interface{}) {
+	positional := *params
+	positional.placeholder = func(int) string { return "?" }
+	query, args := positional.GenerateSQL()
+
+	query, args = expandPlaceholders(strings.ReplaceAll(query, ":", "::"), args, nil, func(index int) string {
+		return ":arg" + strconv.Itoa(index)
+	})
+	namedArgs := make(map[string]interface{}, len(args))
+	for i, arg := range args {
+		namedArgs["arg"+strconv.Itoa(i+1)] = arg
+	}
+	return query, namedArgs
+}
+
+// QueryParts holds the clauses of the paginated query, without their keywords
+// and with '?' placeholders, for composing them into another query builder.
+type QueryParts struct {
+	Columns    []string
+	ColumnArgs []interface{}
+	From       string
+	Joins      []string
+	Where      string
+	WhereArgs  []interface{}
+	GroupBy    []string
+	Having     string
+	HavingArgs []interface{}
+	OrderBy    string
+	Limit      int
+	Offset     int64
+	// Pagination is the LIMIT and OFFSET, or OFFSET and FETCH, clause of the
+	// dialect. It's empty with JoinSafePagination, which paginates the ids in
+	// a Where subquery instead.
+	Pagination     string
+	PaginationArgs []interface{}
+}
+
+// Parts returns the clauses of the paginated query. Slice arguments are
+// already expanded into one placeholder per element, and the columns include
+// the search rank the ORDER BY may refer to.
+func (params *QueryParams) Parts() QueryParts {
+	question := func(int) string { return "?" }
+	whereClauses, whereArgs := params.buildWhereClauses()
+	whereClauses, whereArgs = withClauses(whereClauses, whereArgs, params.DataWhereClauses, params.DataWhereArgs)
+	where := strings.Join(whereClauses, " AND ")
+	limitOffsetClause, limitOffsetArgs := params.buildLimitOffsetClause()
+	if params.joinSafe() {
+		where = params.joinSafeCondition(where, params.orderBy(false), limitOffsetClause)
+		whereArgs = append(whereArgs[:len(whereArgs):len(whereArgs)], limitOffsetArgs...)
+		whereClauses = append(whereClauses[:len(whereClauses):len(whereClauses)], where)
+		limitOffsetClause, limitOffsetArgs = "", nil
+	}
+	where, whereArgs = expandPlaceholders(where, whereArgs, nil, question)
+
+	columns, orderClause := params.selectColumns(), params.buildOrderClause()
+	var columnArgs []interface{}
+	if params.RawSelect == "" {
+		columnArgs = params.selectFlagArgs()
+		if rankColumn, rankArg := params.rankColumn(); rankColumn != "" {
+			if len(columns) == 0 {
+				columns = []string{"*"}
+			}
+			columns = append(columns[:len(columns):len(columns)], rankColumn)
+			columnArgs = append(columnArgs, rankArg)
+		}
+	}
+	groupClauses, groupArgs := params.buildGroupClauses()
+	parts := QueryParts{
+		Columns:   columns,
+		From:      strings.TrimPrefix(params.fromClause(), "FROM "),
+		Joins:     params.joins(columns, whereClauses, groupClauses, []string{orderClause}),
+		Where:     where,
+		WhereArgs: whereArgs,
+		OrderBy:   strings.TrimPrefix(orderClause, "ORDER BY "),
+		Limit:     params.ItemsPerPage,
+		Offset:    params.offset(),
+	}
+	if len(whereClauses) == 0 {
+		parts.WhereArgs = nil
+	}
+	if limitOffsetClause != "" {
+		parts.Pagination, parts.PaginationArgs = expandPlaceholders(limitOffsetClause, limitOffsetArgs, nil, question)
+	}
+	if params.RawSelect != "" {
+		parts.Columns[0], parts.ColumnArgs = expandPlaceholders(params.RawSelect, params.RawSelectArgs, nil, question)
+	} else if len(columnArgs) > 0 {
+		column, columnArgs := expandPlaceholders(strings.Join(columns, ", "), columnArgs, nil, question)
+		parts.Columns, parts.ColumnArgs = []string{column}, columnArgs
+	}
+
+	if len(groupClauses) > 0 {
+		parts.GroupBy = params.GroupBy
+	}
+	if len(groupClauses) > 1 {
+		parts.Having, parts.HavingArgs = expandPlaceholders(strings.TrimPrefix(groupClauses[1], "HAVING "), groupArgs, nil, question)
+	}
+	return parts
+}
+
+// generateSQL generates the paginated SQL query using the given WHERE clauses,
+// building it in the buffers when set.
+func (params *QueryParams) generateSQL(whereClauses []string, whereArgs []interface{}, buffers *sqlBuffers) (string, []interface{}) {
+	clauses := make([]string, 0, 8)
+	args := make([]interface{}, 0, len(whereArgs)+3)
+	var finalArgs []interface{}
+	if buffers != nil {
+		clauses, args, finalArgs = buffers.clauses[:0], buffers.args[:0], buffers.finalArgs[:0]
+	}
+
+	whereClauses, whereArgs = withClauses(whereClauses, whereArgs, params.DataWhereClauses, params.DataWhereArgs)
+
+	// SELECT clause
+	selectClause := "SELECT "
+	columns := params.selectColumns()
+	if params.RawSelect != "" {
+		args = append(args, params.RawSelectArgs...)
+	} else {
+		args = append(args, params.selectFlagArgs()...)
+		if rankColumn, rankArg := params.rankColumn(); rankColumn != "" {
+			if len(columns) == 0 {
+				columns = []string{"*"}
+			}
+			columns = append(columns[:len(columns):len(columns)], rankColumn)
+			args = append(args, rankArg)
+		}
+	}
+	if len(columns) > 0 {
+		selectClause += strings.Join(columns, ", ")
+	} else {
+		selectClause += "*"
+	}
+	clauses = append(clauses, selectClause)
+
+	// FROM clause
+	clauses = append(clauses, params.fromClause())
+
+	orderClause := params.buildOrderClause()
+	limitOffsetClause, limitOffsetArgs := params.buildLimitOffsetClause()
+	groupClauses, groupArgs := params.buildGroupClauses()
+
+	// JOIN clauses
+	if joins := params.joins(columns, whereClauses, groupClauses, []string{orderClause}); len(joins) > 0 {
+		clauses = append(clauses, strings.Join(joins, " "))
+	}
+
+	// WHERE clause
+	if params.joinSafe() {
+		clauses = append(clauses, "WHERE "+params.joinSafeCondition(strings.Join(whereClauses, " AND "), params.orderBy(false), limitOffsetClause))
+		args = append(args, whereArgs...)
+		args = append(args, limitOffsetArgs...)
+	} else if len(whereClauses) > 0 {
+		clauses = append(clauses, "WHERE "+strings.Join(whereClauses, " AND "))
+		args = append(args, whereArgs...)
+	}
+
+	// GROUP BY and HAVING clauses
+	clauses = append(clauses, groupClauses...)
+	args = append(args, groupArgs...)
+
+	// ORDER BY clause
+	if orderClause != "" {
+		clauses = append(clauses, orderClause)
+	}
+
+	// LIMIT and OFFSET
+	if !params.joinSafe() {
+		clauses = append(clauses, limitOffsetClause)
+		args = append(args, limitOffsetArgs...)
+	}
+
+	// Combine all clauses
+	query := strings.Join(clauses, " ")
+
+	// Replace placeholders
+	placeholder := params.Dialect.placeholder
+	if params.placeholder != nil {
+		placeholder = params.placeholder
+	}
+	query, finalArgs = expandPlaceholders(query, args, finalArgs, placeholder)
+	if buffers != nil {
+		// Copy the clauses back rather than keeping the slice, which would
+		// move it to the heap for GenerateSQL too.
+		buffers.clauses = append(buffers.clauses[:0], clauses...)
+		buffers.args, buffers.finalArgs = args, finalArgs
+	}
+
+	if params.MetricsHook != nil {
+		params.MetricsHook(params.metrics(orderClause != ""))
+	}
+	return query, finalArgs
+}
+
+// GenerateExplainSQL generates the paginated SQL query prefixed with the
+// EXPLAIN statement of the dialect, and its arguments. It doesn't report
+// metrics, and returns an error for Oracle and SQL Server.
+func (params *QueryParams) GenerateExplainSQL() (string, []interface{}, error) {
+	prefix, err := params.Dialect.explainPrefix(params.ExplainAnalyze)
+	if err != nil {
+		return "", nil, err
+	}
+	query, args := params.generateUnreported()
+	return prefix + query, args, nil
+}
+
+// GenerateCountQuery generates the SQL query for counting total records.
+func (params *QueryParams) GenerateCountQuery() (string, []interface{}) {
+	whereClauses, whereArgs := params.buildWhereClauses()
+	return params.generateCountQuery(whereClauses, whereArgs)
+}
+
+// generateCountQuery generates the count query using the given WHERE clauses.
+func (params *QueryParams) generateCountQuery(whereClauses []string, whereArgs []interface{}) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	whereClauses, whereArgs = withClauses(whereClauses, whereArgs, params.CountWhereClauses, params.CountWhereArgs)
+
+	if estimator := params.estimatedCount(); params.Vacuum && estimator != nil {
+		return params.Dialect.replacePlaceholders(estimator(params))
+	}
+
+	// SELECT COUNT clause
+	clauses = append(clauses, fmt.Sprintf("SELECT COUNT(%s)", params.idColumnName()))
+
+	// FROM clause
+	clauses = append(clauses, params.fromClause())
+
+	// JOIN clauses, left out of a join-safe count so it counts parent rows
+	groupClauses, groupArgs := params.buildGroupClauses()
+	if joins := params.joins(whereClauses, groupClauses); len(joins) > 0 && !params.joinSafe() {
+		clauses = append(clauses, strings.Join(joins, " "))
+	}
+
+	// WHERE clause
+	if len(whereClauses) > 0 {
+		clauses = append(clauses, "WHERE "+strings.Join(whereClauses, " AND "))
+		args = append(args, whereArgs...)
+	}
+
+	// Combine all clauses
+	query := strings.Join(clauses, " ")
+
+	// rows returns a row for each counted row, or for each group of a grouped
+	// query, and is what count_estimate estimates.
+	clauses[0] = "SELECT 1"
+
+	// Count the groups instead of the rows of a grouped query
+	if len(params.GroupBy) > 0 {
+		clauses = append(clauses, groupClauses...)
+		args = append(args, groupArgs...)
+		// Oracle doesn't accept AS before a table alias.
+		alias := " AS grouped"
+		if params.Dialect == DialectOracle {
+			alias = " grouped"
+		}
+		query = "SELECT COUNT(*) FROM (" + strings.Join(clauses, " ") + ")" + alias
+	}
+
+	if params.Vacuum && params.Dialect == DialectPostgres {
+		rows, _ := params.Dialect.replacePlaceholders(strings.Join(clauses, " "), args)
+		query, args = params.Dialect.replacePlaceholders(query, args)
+		estimate := estimateCountQuery(rows)
+		if params.EstimateThreshold > 0 {
+			// The estimate casts its placeholders to text, so the exact count
+			// binds the arguments again to keep their own types.
+			n := len(args)
+			exact := dollarPlaceholder.ReplaceAllStringFunc(query, func(placeholder string) string {
+				index, _ := strconv.Atoi(placeholder[1:])
+				return "$" + strconv.Itoa(index+n)
+			})
+			estimate = fmt.Sprintf("SELECT CASE WHEN estimated.count < %d THEN (%s) ELSE estimated.count END FROM (%s AS count) AS estimated;",
+				params.EstimateThreshold, exact, strings.TrimSuffix(estimate, ";"))
+			args = append(args[:n:n], args...)
+		}
+		return estimate, args
+	}
+
+	// Replace placeholders
+	return params.Dialect.replacePlaceholders(query, args)
+}
+
+// estimateCountQuery wraps the query in count_estimate. The query is passed as
+// a string literal with its placeholders concatenated through quote_nullable,
+// so they stay bound to the same arguments instead of becoming text inside the
+// literal.
+func estimateCountQuery(query string) string {
+	var parts []string
+	last := 0
+	for _, match := range dollarPlaceholder.FindAllStringIndex(query, -1) {
+		if match[0] > last {
+			parts = append(parts, "'"+strings.ReplaceAll(query[last:match[0]], "'", "''")+"'")
+		}
+		parts = append(parts, "quote_nullable("+query[match[0]:match[1]]+"::TEXT)")
+		last = match[1]
+	}
+	if last < len(query) {
+		parts = append(parts, "'"+strings.ReplaceAll(query[last:], "'", "''")+"'")
+	}
+	return "SELECT count_estimate(" + strings.Join(parts, " || ") + ");"
+}
+
+// ValidatePage returns an error when the requested page is beyond the last
+// page for the given total number of items.
+func (params *QueryParams) ValidatePage(totalItems int) error {
+	lastPage := 1
+	if params.ItemsPerPage > 0 && totalItems > 0 {
+		lastPage = (totalItems + params.ItemsPerPage - 1) / params.ItemsPerPage
+	}
+	if params.Page < 1 || params.Page > lastPage {
+		return fmt.Errorf("page %d is out of range, last page is %d", params.Page, lastPage)
+	}
+	return nil
+}
+
+// CacheKey returns a deterministic hash of the effective paginated query,
+// suitable as an ETag or cache key. It doesn't report metrics.
+func (params *QueryParams) CacheKey() string {
+	query, args := params.generateUnreported()
+
+	hash := sha256.New()
+	hash.Write([]byte(query))
+	for _, arg := range args {
+		fmt.Fprintf(hash, "\x00%T:%v", arg, arg)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hasWhereConditions reports whether any input of the WHERE clause is set, so
+// unfiltered lists skip assembling it.
+func (params *QueryParams) hasWhereConditions() bool {
+	return params.PartitionColumn != "" || len(params.RowSecurity) > 0 || params.SoftDeleteColumn != "" || len(params.DefaultFilters) > 0 ||
+		(params.Search != "" && len(params.SearchFields) > 0) || len(params.SearchConditions) > 0 ||
+		len(params.MultiSearches) > 0 || params.WeightedSearch != nil || len(params.Filters) > 0 ||
+		len(params.ColumnComparisons) > 0 || len(params.WhereClauses) > 0 || (params.Cursor != nil && params.Cursor.Values != nil)
+}
+
+// buildWhereClauses constructs the WHERE clauses and arguments.
+func (params *QueryParams) buildWhereClauses() ([]string, []interface{}) {
+	return params.whereClausesIn(nil)
+}
+
+// whereClausesIn constructs the WHERE clauses and arguments in the buffers
+// when set.
+func (params *QueryParams) whereClausesIn(buffers *sqlBuffers) ([]string, []interface{}) {
+	if !params.hasWhereConditions() {
+		return nil, nil
+	}
+
+	var whereClauses, searchConditions []string
+	var args []interface{}
+	if buffers != nil {
+		whereClauses, args, searchConditions = buffers.whereClauses[:0], buffers.whereArgs[:0], buffers.searchConditions[:0]
+	}
+
+	// Partition key, first so the partition pruning is easy to spot
+	if params.PartitionColumn != "" {
+		whereClauses = append(whereClauses, params.PartitionColumn+" = ?")
+		args = append(args, params.PartitionValue)
+	}
+
+	// Row-level security predicates
+	for _, rowSecurity := range params.RowSecurity {
+		whereClauses = append(whereClauses, "("+rowSecurity.Clause+")")
+		args = append(args, rowSecurity.Args...)
+	}
+
+	// Soft-deleted rows, always excluded like the row-level security predicates
+	if params.SoftDeleteColumn != "" {
+		whereClauses = append(whereClauses, params.SoftDeleteColumn+" IS NULL")
+	}
+
+	// Default filters
+	if len(params.DefaultFilters) > 0 {
+		defaults := &QueryParams{}
+		for _, option := range params.DefaultFilters {
+			option(defaults)
+		}
+		// The server sets the defaults, so the field guard doesn't apply to them.
+		unguarded := *params
+		unguarded.FieldGuard = nil
+		defaultClauses, defaultArgs := unguarded.buildFilterClauses(defaults.Filters)
+		whereClauses = append(whereClauses, defaultClauses...)
+		args = append(args, defaultArgs...)
+		whereClauses = append(whereClauses, defaults.WhereClauses...)
+		args = append(args, defaults.WhereArgs...)
+	}
+
+	// Search conditions
+	if params.Search != "" && len(params.SearchFields) > 0 {
+		for _, field := range params.SearchFields {
+			columnName := params.guardedColumnName(field, "search")
+			if columnName != "" {
+				clause, arg := params.searchPredicate(SearchContains, columnName, params.redactedTerm(params.Search, field))
+				searchConditions = append(searchConditions, clause)
+				args = append(args, arg)
+			}
+		}
+	}
+	combining := "OR"
+	if strings.EqualFold(params.SearchCombining, "AND") && len(params.SearchConditions) > 0 {
+		combining = "AND"
+		if len(searchConditions) > 0 {
+			searchConditions = []string{params.group(searchConditions, "OR")}
+		}
+	}
+	for _, condition := range params.SearchConditions {
+		columnName := params.guardedColumnName(condition.Field, "search")
+		if columnName != "" {
+			clause, arg := params.searchPredicate(condition.Mode, columnName, params.redactedTerm(condition.Term, condition.Field))
+			searchConditions = append(searchConditions, clause)
+			args = append(args, arg)
+		}
+	}
+	if len(searchConditions) > 0 {
+		searchGroup := params.group(searchConditions, combining)
+		if len(params.OrFilters) > 0 {
+			orFilters := params.orFilterParams()
+			orClauses, orArgs := params.buildFilterClauses(orFilters.Filters)
+			orClauses = append(orClauses, orFilters.WhereClauses...)
+			orArgs = append(orArgs, orFilters.WhereArgs...)
+			if len(orClauses) > 0 {
+				searchGroup = "(" + searchGroup + " OR " + params.group(orClauses, "AND") + ")"
+				args = append(args, orArgs...)
+			}
+		}
+		whereClauses = append(whereClauses, searchGroup)
+	}
+
+	// Multi-term search groups
+	for _, multiSearch := range params.MultiSearches {
+		var multiConditions []string
+		for _, field := range multiSearch.Fields {
+			columnName := params.guardedColumnName(field, "search")
+			if columnName == "" {
+				continue
+			}
+			for _, term := range multiSearch.Terms {
+				clause, arg := params.searchPredicate(SearchContains, columnName, params.redactedTerm(term, field))
+				multiConditions = append(multiConditions, clause)
+				args = append(args, arg)
+			}
+		}
+		if len(multiConditions) > 0 {
+			whereClauses = append(whereClauses, params.group(multiConditions, "OR"))
+		}
+	}
+
+	// Weighted full-text search
+	if vector := params.weightedVector(); vector != "" {
+		whereClauses = append(whereClauses, vector+" @@ plainto_tsquery(?)")
+		args = append(args, params.redactedTerm(params.WeightedSearch.Term, params.weightedFields()...))
+	}
+
+	// Filter conditions
+	filterClauses, filterArgs := params.filterClausesIn(params.Filters, buffers)
+	whereClauses = append(whereClauses, filterClauses...)
+	args = append(args, filterArgs...)
+
+	// Keyset cursor
+	if clause, cursorArgs := params.cursorPredicate(); clause != "" {
+		whereClauses = append(whereClauses, clause)
+		args = append(args, cursorArgs...)
+	}
+
+	// Column comparisons
+	whereClauses = append(whereClauses, params.buildColumnComparisonClauses()...)
+
+	// Additional WHERE clauses
+	if len(params.WhereClauses) > 0 {
+		if len(params.WhereClauses) > 1 && !strings.EqualFold(params.WhereCombining, "AND") {
+			// Keep the combined clauses from escaping the other conditions.
+			whereClauses = append(whereClauses, "("+strings.Join(params.WhereClauses, fmt.Sprintf(" %s ", params.WhereCombining))+")")
+		} else {
+			whereClauses = append(whereClauses, strings.Join(params.WhereClauses, fmt.Sprintf(" %s ", params.WhereCombining)))
+		}
+		args = append(args, params.WhereArgs...)
+	}
+
+	if buffers != nil {
+		buffers.whereClauses, buffers.whereArgs = whereClauses, args
+		buffers.searchConditions = append(buffers.searchConditions[:0], searchConditions...)
+	}
+	return whereClauses, args
+}
+
+// referencedFields returns the fields referenced by the select fields, search,
+// weighted search, sort, filters, OR filters, column comparisons and cursor.
+func (params *QueryParams) referencedFields() []string {
+	fields := append(append(append([]string{}, params.Fields...), params.SearchFields...), params.SortColumns...)
+	for _, condition := range params.SearchConditions {
+		fields = append(fields, condition.Field)
+	}
+	for _, multiSearch := range params.MultiSearches {
+		fields = append(fields, multiSearch.Fields...)
+	}
+	fields = append(fields, params.weightedFields()...)
+	for _, jsonSort := range params.JSONSorts {
+		fields = append(fields, jsonSort.Field)
+	}
+	for _, nullsSort := range params.NullsSorts {
+		fields = append(fields, nullsSort.Field)
+	}
+	for _, filter := range params.Filters {
+		fields = append(fields, filter.Field)
+	}
+	for _, filter := range params.orFilterParams().Filters {
+		fields = append(fields, filter.Field)
+	}
+	for _, comparison := range params.ColumnComparisons {
+		fields = append(fields, comparison.Left, comparison.Right)
+	}
+	if params.Cursor != nil {
+		fields = append(fields, params.Cursor.Fields...)
+	}
+	return fields
+}
+
+// group joins the conditions with the operator inside parentheses, which are
+// omitted for a single condition when MinimalParens is set.
+func (params *QueryParams) group(conditions []string, operator string) string {
+	if len(conditions) == 1 && params.MinimalParens {
+		return conditions[0]
+	}
+	return "(" + strings.Join(conditions, " "+operator+" ") + ")"
+}
+
+// String returns the name of the search mode.
+func (mode SearchMode) String() string {
+	switch mode {
+	case SearchExact:
+		return "exact"
+	case SearchPrefix:
+		return "prefix"
+	case SearchSuffix:
+		return "suffix"
+	default:
+		return "contains"
+	}
+}
+
+// searchPredicate returns the search clause for the column and its argument.
+func (params *QueryParams) searchPredicate(mode SearchMode, columnName, term string) (string, interface{}) {
+	switch mode {
+	case SearchExact:
+		return params.Dialect.textCast(columnName) + " = ?", term
+	case SearchPrefix:
+		return params.likePredicate(columnName, term, false, true)
+	case SearchSuffix:
+		return params.likePredicate(columnName, term, true, false)
+	default:
+		return params.likePredicate(columnName, term, true, true)
+	}
+}
+
+// likePredicate returns a case-insensitive LIKE clause of the dialect matching the term with leading and/or
+// trailing wildcards. With SQLSideWildcards the argument is the raw term and
+// the wildcards are concatenated in SQL.
+func (params *QueryParams) likePredicate(columnName, term string, leading, trailing bool) (string, interface{}) {
+	if !params.SQLSideWildcards {
+		if leading {
+			term = "%" + term
+		}
+		if trailing {
+			term += "%"
+		}
+		return params.Dialect.ilike(params.Dialect.textCast(columnName), "?"), term
+	}
+
+	parts := []string{"?"}
+	if leading {
+		parts = append([]string{"'%'"}, parts...)
+	}
+	if trailing {
+		parts = append(parts, "'%'")
+	}
+	return params.Dialect.ilike(params.Dialect.textCast(columnName), params.Dialect.concat(parts...)), term
+}
+
+// buildGroupClauses constructs the GROUP BY and HAVING clauses and arguments.
+func (params *QueryParams) buildGroupClauses() ([]string, []interface{}) {
+	if len(params.GroupBy) == 0 {
+		return nil, nil
+	}
+
+	clauses := []string{"GROUP BY " + strings.Join(params.GroupBy, ", ")}
+	if len(params.HavingClauses) == 0 {
+		return clauses, nil
+	}
+	having := params.HavingClauses[0]
+	if len(params.HavingClauses) > 1 {
+		having = params.group(params.HavingClauses, params.HavingCombining)
+	}
+	return append(clauses, "HAVING "+having), params.HavingArgs
+}
+
+// buildOrderClause constructs the ORDER BY clause.
+func (params *QueryParams) buildOrderClause() string {
+	return params.orderBy(true)
+}
+
+// orderBy constructs the ORDER BY clause, ordering by the search rank when
+// rank is set. The join-safe id subquery leaves it out, since it doesn't
+// select the rank column.
+func (params *QueryParams) orderBy(rank bool) string {
+	var sortClauses []string
+	sorted := func(columnName string) bool {
+		return slices.ContainsFunc(sortClauses, func(clause string) bool {
+			return strings.HasPrefix(clause, columnName+" ") || strings.HasPrefix(clause, "CASE WHEN "+columnName+" IS NULL ")
+		})
 	}
 
-	// WHERE clause
-	whereClauses, whereArgs := params.buildWhereClauses()
-	if len(whereClauses) > 0 {
-		clauses = append(clauses, "WHERE "+strings.Join(whereClauses, " AND "))
-		args = append(args, whereArgs...)
+	// The cursor columns come first, the other sort columns break their ties.
+	if params.Cursor != nil {
+		for i, field := range params.Cursor.Fields {
+			if columnName := params.guardedColumnName(field, "sort"); columnName != "" && i < len(params.Cursor.Directions) {
+				sortClauses = append(sortClauses, params.sortClause(columnName, strings.ToUpper(params.Cursor.Directions[i])))
+			}
+		}
 	}
 
-	// Combine all clauses
-	query := strings.Join(clauses, " ")
+	if len(params.SortDirections) == len(params.SortColumns) || params.PadSortDirections {
+		for i, column := range params.SortColumns {
+			columnName := params.guardedColumnName(column, "sort")
+			if columnName != "" && !sorted(columnName) {
+				direction := "ASC"
+				if i < len(params.SortDirections) && strings.ToLower(params.SortDirections[i]) == "true" {
+					direction = "DESC"
+				}
+				sortClauses = append(sortClauses, params.sortClause(columnName, direction))
+			}
+		}
+	}
 
-	// Replace placeholders
-	query, args = replacePlaceholders(query, args)
-
-	if params.Vacuum {
-		countQuery := "SELECT count_estimate('" + query + "');"
-		countQuery = strings.Replace(countQuery, "COUNT(id)", "1", -1)
-		re := regexp.MustCompile(`(\$[0-9]+)`)
-		countQuery = re.ReplaceAllStringFunc(countQuery, func(match string) string {
-			return "''" + match + "''"
-		})
-		return countQuery, args
+	for _, nullsSort := range params.NullsSorts {
+		columnName := params.guardedColumnName(nullsSort.Field, "sort")
+		if columnName != "" && !sorted(columnName) {
+			nulls := NullsOrder(strings.ToUpper(string(nullsSort.Nulls)))
+			sortClauses = append(sortClauses, params.Dialect.nullsSortClause(columnName, strings.ToUpper(nullsSort.Direction), nulls))
+		}
 	}
 
-	return query, args
-}
+	for _, jsonSort := range params.JSONSorts {
+		columnName := params.guardedColumnName(jsonSort.Field, "sort")
+		if columnName != "" {
+			sortClauses = append(sortClauses, params.sortClause(params.Dialect.jsonPath(columnName, jsonSort.Path), strings.ToUpper(jsonSort.Direction)))
+		}
+	}
 
-// buildWhereClauses constructs the WHERE clauses and arguments.
-func (params *QueryParams) buildWhereClauses() ([]string, []interface{}) {
-	var whereClauses []string
-	var args []interface{}
+	if rankColumn, _ := params.rankColumn(); rank && len(sortClauses) == 0 && rankColumn != "" {
+		sortClauses = append(sortClauses, params.sortClause(params.rankAlias(), "DESC"))
+	}
 
-	// Search conditions
-	if params.Search != "" && len(params.SearchFields) > 0 {
-		var searchConditions []string
-		for _, field := range params.SearchFields {
-			columnName := getFieldName(field, "json", "paginate", params.Struct)
-			if columnName != "" {
-				searchConditions = append(searchConditions, fmt.Sprintf("%s::TEXT ILIKE ?", columnName))
-				args = append(args, "%"+params.Search+"%")
-			}
+	if params.RecencyColumn != "" && !sorted(params.RecencyColumn) {
+		sortClauses = append(sortClauses, params.sortClause(params.RecencyColumn, "DESC"))
+	}
+
+	if len(sortClauses) > 0 {
+		return "ORDER BY " + strings.Join(sortClauses, ", ")
+	}
+	return ""
+}
+
+// rankColumn returns the select column ranking the rows by the full-text
+// relevance of the weighted search, or of the search term in the search fields
+// with SearchRank, and its argument. It returns "" without a rank or a search.
+func (params *QueryParams) rankColumn() (string, interface{}) {
+	if vector := params.weightedVector(); vector != "" {
+		return fmt.Sprintf("ts_rank(%s, plainto_tsquery(?)) AS %s", vector, params.rankAlias()), params.redactedTerm(params.WeightedSearch.Term, params.weightedFields()...)
+	}
+	if params.SearchRank == "" || params.Search == "" {
+		return "", nil
+	}
+	var columnNames []string
+	for _, field := range params.SearchFields {
+		if columnName := params.guardedColumnName(field, "search"); columnName != "" {
+			columnNames = append(columnNames, columnName+"::TEXT")
 		}
-		if len(searchConditions) > 0 {
-			whereClauses = append(whereClauses, "("+strings.Join(searchConditions, " OR ")+")")
+	}
+	if len(columnNames) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("ts_rank(to_tsvector(concat_ws(' ', %s)), plainto_tsquery(?)) AS %s", strings.Join(columnNames, ", "), params.SearchRank), params.redactedTerm(params.Search, params.SearchFields...)
+}
+
+// rankAlias returns the alias of the rank column, "rank" unless set by SearchRank.
+func (params *QueryParams) rankAlias() string {
+	if params.SearchRank != "" {
+		return params.SearchRank
+	}
+	return "rank"
+}
+
+// weightedVector returns the tsvector of the weighted search fields, in field
+// order, or "" without a weighted search.
+func (params *QueryParams) weightedVector() string {
+	if params.WeightedSearch == nil || params.WeightedSearch.Term == "" {
+		return ""
+	}
+	var vectors []string
+	for _, field := range params.weightedFields() {
+		if columnName := params.guardedColumnName(field, "search"); columnName != "" {
+			vectors = append(vectors, fmt.Sprintf("setweight(to_tsvector(coalesce(%s::TEXT, '')), '%s')", columnName, weightLabel(params.WeightedSearch.Weights[field])))
 		}
 	}
+	return strings.Join(vectors, " || ")
+}
 
-	// Additional WHERE clauses
-	if len(params.WhereClauses) > 0 {
-		whereClauses = append(whereClauses, strings.Join(params.WhereClauses, fmt.Sprintf(" %s ", params.WhereCombining)))
-		args = append(args, params.WhereArgs...)
+// weightedFields returns the sorted fields of the weighted search.
+func (params *QueryParams) weightedFields() []string {
+	if params.WeightedSearch == nil {
+		return nil
 	}
+	fields := make([]string, 0, len(params.WeightedSearch.Weights))
+	for field := range params.WeightedSearch.Weights {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
 
-	return whereClauses, args
+// weightLabel maps a weight to the label of the largest default ts_rank weight
+// not above it: A (1.0), B (0.4), C (0.2) or D (0.1).
+func weightLabel(weight float64) string {
+	switch {
+	case weight >= 1:
+		return "A"
+	case weight >= 0.4:
+		return "B"
+	case weight >= 0.2:
+		return "C"
+	}
+	return "D"
 }
 
-// buildOrderClause constructs the ORDER BY clause.
-func (params *QueryParams) buildOrderClause() string {
+// fromClause returns the FROM clause with the schema-qualified table.
+func (params *QueryParams) fromClause() string {
+	schema, table := params.schemaTable()
+	alias := ""
+	if i := strings.IndexAny(params.Table, " \t"); i >= 0 {
+		alias = params.Table[i:]
+	}
+	if params.QuoteTable {
+		table = params.Dialect.quote(table)
+		if schema != "" {
+			schema = params.Dialect.quote(schema)
+		}
+	}
+	if schema != "" {
+		table = schema + "." + table
+	}
+	if params.TableAlias != "" {
+		// Oracle doesn't accept AS before a table alias.
+		alias = " AS " + params.TableAlias
+		if params.Dialect == DialectOracle {
+			alias = " " + params.TableAlias
+		}
+	}
+	return "FROM " + table + alias
+}
 
-	if len(params.SortColumns) == 0 || len(params.SortDirections) != len(params.SortColumns) {
-		fmt.Println(params.SortColumns)
-		fmt.Println(params.SortDirections)
-		return ""
+// schemaTable returns the schema and the name of the table without its alias.
+// A schema-qualified table like "public.users" is split, and its schema takes
+// precedence over the Schema option so it isn't prefixed twice.
+func (params *QueryParams) schemaTable() (string, string) {
+	table := params.Table
+	if i := strings.IndexAny(table, " \t"); i >= 0 {
+		table = table[:i]
 	}
+	if i := strings.LastIndexByte(table, '.'); i > 0 && !strings.ContainsAny(table, "\"`[") {
+		return table[:i], table[i+1:]
+	}
+	return params.Schema, table
+}
 
-	var sortClauses []string
-	for i, column := range params.SortColumns {
-		columnName := getFieldName(column, "json", "paginate", params.Struct)
-		if columnName != "" {
-			direction := "ASC"
-			if strings.ToLower(params.SortDirections[i]) == "true" {
-				direction = "DESC"
-			}
-			sortClauses = append(sortClauses, fmt.Sprintf("%s %s", columnName, direction))
+// selectColumns returns the raw select, or the custom columns, the dialect
+// columns, the resolved sparse fieldset columns and the flag columns.
+func (params *QueryParams) selectColumns() []string {
+	if params.RawSelect != "" {
+		return []string{params.RawSelect}
+	}
+	if len(params.Fields) == 0 && len(params.DialectColumns) == 0 && len(params.SelectFlags) == 0 {
+		return params.Columns
+	}
+	columns := append([]string{}, params.Columns...)
+	for _, column := range params.DialectColumns {
+		columns = append(columns, column.Expressions[params.Dialect]+" AS "+column.Alias)
+	}
+	for _, field := range params.Fields {
+		if columnName := params.guardedColumnName(field, "select"); columnName != "" {
+			columns = append(columns, columnName)
+		}
+	}
+	if len(columns) == 0 {
+		// Rejected fields select no column rather than every one.
+		if len(params.Fields) > 0 {
+			columns = append(columns, "NULL")
+		} else if len(params.SelectFlags) > 0 {
+			columns = append(columns, "*")
 		}
 	}
+	for _, flag := range params.SelectFlags {
+		columns = append(columns, "("+flag.Expression+") AS "+flag.Alias)
+	}
+	return columns
+}
 
-	fmt.Println(sortClauses)
-	if len(sortClauses) > 0 {
-		return "ORDER BY " + strings.Join(sortClauses, ", ")
+// selectFlagArgs returns the arguments of the flag columns in select order.
+func (params *QueryParams) selectFlagArgs() []interface{} {
+	var args []interface{}
+	for _, flag := range params.SelectFlags {
+		args = append(args, flag.Args...)
 	}
-	return ""
+	return args
+}
+
+// sortClause returns the ORDER BY entry for the expression and direction.
+func (params *QueryParams) sortClause(expression, direction string) string {
+	return params.Dialect.nullsSortClause(expression, direction, params.NullsOrdering)
+}
+
+// validate checks the JSON path segments and the direction.
+func (jsonSort JSONSort) validate() error {
+	for _, segment := range strings.Split(jsonSort.Path, ".") {
+		if !jsonPathSegment.MatchString(segment) {
+			return fmt.Errorf("invalid JSON path %q for field %q", jsonSort.Path, jsonSort.Field)
+		}
+	}
+	switch strings.ToUpper(jsonSort.Direction) {
+	case "ASC", "DESC":
+		return nil
+	}
+	return fmt.Errorf("invalid sort direction %q for field %q", jsonSort.Direction, jsonSort.Field)
+}
+
+// validate checks the direction and the NULLS placement.
+func (nullsSort NullsSort) validate() error {
+	switch strings.ToUpper(nullsSort.Direction) {
+	case "ASC", "DESC":
+	default:
+		return fmt.Errorf("invalid sort direction %q for field %q", nullsSort.Direction, nullsSort.Field)
+	}
+	switch NullsOrder(strings.ToUpper(string(nullsSort.Nulls))) {
+	case NullsFirst, NullsLast:
+		return nil
+	}
+	return fmt.Errorf("invalid nulls order %q for field %q, expected FIRST or LAST", nullsSort.Nulls, nullsSort.Field)
 }
 
 // buildLimitOffsetClause constructs the LIMIT and OFFSET clauses.
@@ -330,50 +2113,513 @@ func (params *QueryParams) buildLimitOffsetClause() (string, []interface{}) {
 	var clauses []string
 	var args []interface{}
 
-	clauses = append(clauses, "LIMIT ?")
-	args = append(args, params.ItemsPerPage)
+	// value emits a bound placeholder, or with InlineLimitOffset the integer
+	// literal itself; both values are ints, so inlining can't inject SQL.
+	value := func(n int64) string {
+		if params.InlineLimitOffset {
+			return strconv.FormatInt(max(n, 0), 10)
+		}
+		args = append(args, int(n))
+		return "?"
+	}
+
+	if params.Dialect == DialectOracle || params.Dialect == DialectSQLServer || params.AnsiLimit {
+		if !params.NoOffset && params.Cursor == nil {
+			clauses = append(clauses, "OFFSET "+value(params.offset())+" ROWS")
+		} else if params.Dialect == DialectSQLServer {
+			// SQL Server only accepts FETCH after an OFFSET.
+			clauses = append(clauses, "OFFSET 0 ROWS")
+		}
+		fetch := "NEXT"
+		if params.AnsiLimit {
+			fetch = "FIRST"
+		}
+		clauses = append(clauses, "FETCH "+fetch+" "+value(int64(params.ItemsPerPage))+" ROWS ONLY")
+		return strings.Join(clauses, " "), args
+	}
+
+	clauses = append(clauses, "LIMIT "+value(int64(params.ItemsPerPage)))
 
-	if !params.NoOffset {
-		offset := (params.Page - 1) * params.ItemsPerPage
-		clauses = append(clauses, "OFFSET ?")
-		args = append(args, offset)
+	if !params.NoOffset && params.Cursor == nil {
+		clauses = append(clauses, "OFFSET "+value(params.offset()))
 	}
 
 	return strings.Join(clauses, " "), args
 }
 
+// withClauses returns the where clauses and arguments followed by the extra
+// ones, without modifying the given slices.
+func withClauses(clauses []string, args []interface{}, extraClauses []string, extraArgs []interface{}) ([]string, []interface{}) {
+	if len(extraClauses) == 0 {
+		return clauses, args
+	}
+	return append(clauses[:len(clauses):len(clauses)], extraClauses...), append(args[:len(args):len(args)], extraArgs...)
+}
+
+// sortConditions sorts the conditions whose order doesn't change the results.
+// Filters joined with OR keep their order, since it defines the OR groups.
+func (params *QueryParams) sortConditions() {
+	params.SearchFields = append([]string(nil), params.SearchFields...)
+	sort.Strings(params.SearchFields)
+	sort.SliceStable(params.SearchConditions, func(i, j int) bool {
+		return fmt.Sprint(params.SearchConditions[i]) < fmt.Sprint(params.SearchConditions[j])
+	})
+	if !hasOrFilters(params.Filters) {
+		sort.SliceStable(params.Filters, func(i, j int) bool {
+			return fmt.Sprint(params.Filters[i]) < fmt.Sprint(params.Filters[j])
+		})
+	}
+	sort.SliceStable(params.ColumnComparisons, func(i, j int) bool {
+		return fmt.Sprint(params.ColumnComparisons[i]) < fmt.Sprint(params.ColumnComparisons[j])
+	})
+}
+
+// idColumnName returns the id column memoized by NewPaginator, resolving it
+// for params built without it.
+func (params *QueryParams) idColumnName() string {
+	if params.idColumn != "" {
+		return params.idColumn
+	}
+	return params.resolveIDColumn()
+}
+
+// resolveIDColumn returns the CountColumn, or the column of the id field, or
+// "id" when it can't be resolved.
+func (params *QueryParams) resolveIDColumn() string {
+	if params.CountColumn != "" {
+		return params.CountColumn
+	}
+	columnName := params.columnName("id")
+	if columnName == "" {
+		return "id"
+	}
+	if params.TableAlias != "" {
+		// The id column may name the table with or without its schema.
+		schema, table := params.schemaTable()
+		prefixes := []string{table + "."}
+		if schema != "" {
+			prefixes = append(prefixes, schema+"."+table+".")
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(columnName, prefix) {
+				return params.TableAlias + "." + strings.TrimPrefix(columnName, prefix)
+			}
+		}
+	}
+	return columnName
+}
+
+// joins returns the join clauses, with the conditional joins whose table alias
+// is referenced by the clauses.
+func (params *QueryParams) joins(clauses ...[]string) []string {
+	if len(params.ConditionalJoins) == 0 {
+		return params.Joins
+	}
+	joins := params.Joins[:len(params.Joins):len(params.Joins)]
+	for _, join := range params.ConditionalJoins {
+		words := strings.Fields(join.Table)
+		if len(words) == 0 {
+			continue
+		}
+		alias := words[len(words)-1]
+		if slices.ContainsFunc(clauses, func(clauses []string) bool {
+			return slices.ContainsFunc(clauses, func(clause string) bool { return referencesAlias(clause, alias) })
+		}) {
+			joins = append(joins, "LEFT JOIN "+join.Table+" ON "+join.Condition)
+		}
+	}
+	return joins
+}
+
+// referencesAlias reports whether the clause references a column of the alias,
+// as in alias.column.
+func referencesAlias(clause, alias string) bool {
+	for offset := 0; ; {
+		index := strings.Index(clause[offset:], alias+".")
+		if index < 0 {
+			return false
+		}
+		index += offset
+		if index == 0 || !isIdentifierByte(clause[index-1]) {
+			return true
+		}
+		offset = index + 1
+	}
+}
+
+// isIdentifierByte reports whether the byte can be part of a qualified identifier.
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '.' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// joinSafe reports whether the base table ids are paginated in a subquery before joining.
+func (params *QueryParams) joinSafe() bool {
+	return params.JoinSafePagination && len(params.Joins) > 0
+}
+
+// joinSafeCondition returns the condition paginating the ids of the base table
+// before joining, so the LIMIT counts parent rows instead of the fanned-out
+// joined rows. MySQL rejects LIMIT in an IN subquery, so the ids are selected
+// through a derived table there.
+func (params *QueryParams) joinSafeCondition(where, orderClause, limitOffsetClause string) string {
+	subquery := []string{"SELECT " + params.idColumnName(), params.fromClause()}
+	if where != "" {
+		subquery = append(subquery, "WHERE "+where)
+	}
+	if orderClause != "" {
+		subquery = append(subquery, orderClause)
+	}
+	subquery = append(subquery, limitOffsetClause)
+	ids := strings.Join(subquery, " ")
+	if params.Dialect == DialectMySQL {
+		ids = "SELECT * FROM (" + ids + ") AS paginated_ids"
+	}
+	return params.idColumnName() + " IN (" + ids + ")"
+}
+
+// columnName resolves a json field name to its database column.
+func (params *QueryParams) columnName(field string) string {
+	if params.Struct == nil {
+		return ""
+	}
+	if params.CaseInsensitiveFields {
+		field = getFoldedFieldName(field, params.Struct)
+	}
+	columnName, err := getFieldName(field, "json", "paginate", params.Struct)
+	if err != nil {
+		return ""
+	}
+	if columnName == "" && params.AutoColumns {
+		columnName = getAutoFieldName(field, params.Struct)
+	}
+	return columnName
+}
+
+// offset computes the OFFSET using int64 arithmetic. Negative results are
+// clamped to zero and results that overflow or exceed MaxOffset are clamped to
+// MaxOffset (or the largest int when unset).
+func (params *QueryParams) offset() int64 {
+	page := int64(params.Page)
+	itemsPerPage := int64(params.ItemsPerPage)
+	if page <= 1 || itemsPerPage <= 0 {
+		return 0
+	}
+
+	maxOffset := int64(math.MaxInt)
+	if params.MaxOffset > 0 && params.MaxOffset < maxOffset {
+		maxOffset = params.MaxOffset
+	}
+
+	if page-1 > maxOffset/itemsPerPage {
+		return maxOffset
+	}
+	return (page - 1) * itemsPerPage
+}
+
+// metrics collects the QueryMetrics of the current params.
+func (params *QueryParams) metrics(sorted bool) QueryMetrics {
+	m := QueryMetrics{
+		Filters: map[string]int{},
+		Joins:   len(params.Joins),
+		Sorted:  sorted,
+		Limit:   params.ItemsPerPage,
+	}
+
+	if params.Search != "" {
+		for _, field := range params.SearchFields {
+			if params.guardedColumnName(field, "search") != "" {
+				m.Filters["search"]++
+			}
+		}
+	}
+	for _, condition := range params.SearchConditions {
+		if params.guardedColumnName(condition.Field, "search") != "" {
+			m.Filters["search"]++
+		}
+	}
+	if m.Filters["search"] > 0 && len(params.OrFilters) > 0 {
+		orFilters := params.orFilterParams()
+		for _, filter := range orFilters.Filters {
+			if params.guardedColumnName(filter.Field, string(filter.Operator)) != "" {
+				m.Filters[string(filter.Operator)]++
+			}
+		}
+		if len(orFilters.WhereClauses) > 0 {
+			m.Filters["where"] += len(orFilters.WhereClauses)
+		}
+	}
+	for _, multiSearch := range params.MultiSearches {
+		for _, field := range multiSearch.Fields {
+			if params.guardedColumnName(field, "search") != "" {
+				m.Filters["search"] += len(multiSearch.Terms)
+			}
+		}
+	}
+	for _, filter := range params.Filters {
+		if params.guardedColumnName(filter.Field, string(filter.Operator)) != "" {
+			m.Filters[string(filter.Operator)]++
+		}
+	}
+	for _, comparison := range params.ColumnComparisons {
+		if params.guardedColumnName(comparison.Left, "compare") != "" && params.guardedColumnName(comparison.Right, "compare") != "" {
+			m.Filters["column"]++
+		}
+	}
+	if len(params.WhereClauses) > 0 {
+		m.Filters["where"] += len(params.WhereClauses)
+	}
+
+	return m
+}
+
+// orFilterParams returns the params set by the WithOrFilter options.
+func (params *QueryParams) orFilterParams() *QueryParams {
+	orFilters := &QueryParams{}
+	for _, option := range params.OrFilters {
+		option(orFilters)
+	}
+	return orFilters
+}
+
+// guardedColumnName resolves a field used with the op to its column, unless the
+// field guard rejects it.
+func (params *QueryParams) guardedColumnName(field, op string) string {
+	if params.FieldGuard != nil && !params.FieldGuard(field, op) {
+		return ""
+	}
+	return params.columnName(field)
+}
+
 // Helper functions
 
 // replacePlaceholders replaces '?' with positional placeholders like '$1', '$2', etc.
 func replacePlaceholders(query string, args []interface{}) (string, []interface{}) {
-	var newQuery strings.Builder
-	argIndex := 1
-	for _, char := range query {
-		if char == '?' {
-			newQuery.WriteString(fmt.Sprintf("$%d", argIndex))
-			argIndex++
-		} else {
-			newQuery.WriteRune(char)
-		}
+	return DialectPostgres.replacePlaceholders(query, args)
+}
+
+// sliceArg returns the elements of a slice or array argument. Byte slices,
+// like json.RawMessage, and driver.Valuer slices, like array types, are left
+// untouched since drivers treat them as a single value.
+func sliceArg(arg interface{}) ([]interface{}, bool) {
+	if arg == nil {
+		return nil, false
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
 	}
-	return newQuery.String(), args
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// MustField returns the json field name after checking that the model is a
+// struct with a field with that json tag and a paginate column, and panics
+// otherwise. Use it in package-level variables to catch typos in field names
+// at init:
+//
+//	var userStatus = paginate.MustField(User{}, "status")
+func MustField(model interface{}, jsonName string) string {
+	columnName, err := getFieldName(jsonName, "json", "paginate", model)
+	if err != nil {
+		panic("paginate: " + err.Error())
+	}
+	if columnName == "" {
+		panic(fmt.Sprintf("paginate: unknown field %q in %T", jsonName, model))
+	}
+	return jsonName
+}
+
+// fieldNameKey identifies the tag mapping of a struct type cached in fieldNames.
+type fieldNameKey struct {
+	structType     reflect.Type
+	key, keyTarget string
 }
 
+// fieldNames caches the tag mapping of each struct type, built once per type.
+var fieldNames sync.Map
+
 // getFieldName retrieves the column name from struct tags based on the given key.
-func getFieldName(tag, key, keyTarget string, s interface{}) string {
+func getFieldName(tag, key, keyTarget string, s interface{}) (string, error) {
+	rt, err := structType(s)
+	if err != nil {
+		return "", err
+	}
+	cacheKey := fieldNameKey{structType: rt, key: key, keyTarget: keyTarget}
+	names, ok := fieldNames.Load(cacheKey)
+	if !ok {
+		mapping := map[string]string{}
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			tagValue := strings.Split(field.Tag.Get(key), ",")[0]
+			if _, exists := mapping[tagValue]; !exists {
+				mapping[tagValue] = field.Tag.Get(keyTarget)
+			}
+		}
+		names, _ = fieldNames.LoadOrStore(cacheKey, mapping)
+	}
+	return names.(map[string]string)[tag], nil
+}
+
+// structType returns the struct type of the model, a struct or a pointer to
+// one, or an error for any other type.
+func structType(s interface{}) (reflect.Type, error) {
 	rt := reflect.TypeOf(s)
-	if rt.Kind() == reflect.Ptr {
+	if rt != nil && rt.Kind() == reflect.Ptr {
 		rt = rt.Elem()
 	}
-	if rt.Kind() != reflect.Struct {
-		panic("struct type required")
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("model must be a struct, got %T", s)
+	}
+	return rt, nil
+}
+
+// textFields returns the json names of the text fields with a paginate tag,
+// skipping the excluded ones.
+func textFields(s interface{}, exclude []string) []string {
+	rt, err := structType(s)
+	if err != nil {
+		return nil
+	}
+	var fields []string
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if !isTextType(field.Type) || field.Tag.Get("paginate") == "" || jsonName == "" || jsonName == "-" || slices.Contains(exclude, jsonName) {
+			continue
+		}
+		fields = append(fields, jsonName)
+	}
+	return fields
+}
+
+// isTextField reports whether the field of the struct with the json name, or
+// the snake_cased name without a json tag, holds text.
+func isTextField(s interface{}, name string) bool {
+	rt, err := structType(s)
+	if err != nil {
+		return false
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName != name && (jsonName != "" || toSnakeCase(field.Name) != name) {
+			continue
+		}
+		return isTextType(field.Type)
+	}
+	return false
+}
+
+// isTextType reports whether the type holds text: a type of the string kind,
+// like named string types, sql.NullString, or a pointer to either.
+func isTextType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.String || fieldType == reflect.TypeOf(sql.NullString{})
+}
+
+// dropNonTextSearchFields removes the search fields that aren't text fields
+// of the struct, without modifying the slices set by the options.
+func (params *QueryParams) dropNonTextSearchFields() {
+	var searchFields []string
+	for _, field := range params.SearchFields {
+		if isTextField(params.Struct, field) {
+			searchFields = append(searchFields, field)
+		}
+	}
+	params.SearchFields = searchFields
+
+	var conditions []SearchCondition
+	for _, condition := range params.SearchConditions {
+		if isTextField(params.Struct, condition.Field) {
+			conditions = append(conditions, condition)
+		}
+	}
+	params.SearchConditions = conditions
+
+	multiSearches := make([]MultiSearch, 0, len(params.MultiSearches))
+	for _, multiSearch := range params.MultiSearches {
+		var fields []string
+		for _, field := range multiSearch.Fields {
+			if isTextField(params.Struct, field) {
+				fields = append(fields, field)
+			}
+		}
+		multiSearches = append(multiSearches, MultiSearch{Terms: multiSearch.Terms, Fields: fields})
+	}
+	if len(multiSearches) > 0 {
+		params.MultiSearches = multiSearches
+	}
+}
+
+// getAutoFieldName retrieves the snake_cased column name of a field without a
+// paginate tag, matching it by its json tag or its snake_cased name.
+func getAutoFieldName(tag string, s interface{}) string {
+	rt, err := structType(s)
+	if err != nil {
+		return ""
 	}
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
-		tagValue := strings.Split(field.Tag.Get(key), ",")[0]
-		if tagValue == tag {
-			return field.Tag.Get(keyTarget)
+		if field.Tag.Get("paginate") != "" {
+			continue
+		}
+		columnName := toSnakeCase(field.Name)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "-" {
+			continue
+		}
+		if jsonName == tag || (jsonName == "" && columnName == tag) {
+			return columnName
 		}
 	}
 	return ""
 }
+
+// getFoldedFieldName returns the json name of the struct field matching the
+// tag case-insensitively, or the tag itself when no field matches. Fields
+// without a json tag are matched by their snake_cased name.
+func getFoldedFieldName(tag string, s interface{}) string {
+	rt, err := structType(s)
+	if err != nil {
+		return tag
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		if jsonName == "" {
+			jsonName = toSnakeCase(field.Name)
+		}
+		if strings.EqualFold(jsonName, tag) {
+			return jsonName
+		}
+	}
+	return tag
+}
+
+// toSnakeCase converts a Go identifier like CreatedAt into created_at.
+func toSnakeCase(s string) string {
+	runes := []rune(s)
+	var result strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				result.WriteRune('_')
+			}
+			result.WriteRune(unicode.ToLower(r))
+		} else {
+			result.WriteRune(r)
+		}
+	}
+	return result.String()
+}
@@ -2,9 +2,17 @@
 package paginate
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
 )
 
 // User struct used for testing.
@@ -182,15 +190,21 @@ func TestWithWhereCombining(t *testing.T) {
 	}
 }
 
-// TestGetFieldNameInvalidType tests getFieldName with an invalid type.
+// TestGetFieldNameInvalidType tests getFieldName and NewPaginator with an invalid type.
 func TestGetFieldNameInvalidType(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Expected panic for invalid type, but did not panic")
+	for _, model := range []interface{}{"not a struct", new(int), nil} {
+		if _, err := getFieldName("id", "json", "paginate", model); err == nil {
+			t.Errorf("Expected error for %T, got nil", model)
 		}
-	}()
+	}
 
-	getFieldName("id", "json", "paginate", "not a struct")
+	_, err := NewPaginator(WithTable("users"), WithStruct("not a struct"))
+	if err == nil || err.Error() != "model must be a struct, got string" {
+		t.Errorf("Expected model error, got: %v", err)
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(&User{})); err != nil {
+		t.Errorf("Unexpected error for a struct pointer: %v", err)
+	}
 }
 
 // TestReplacePlaceholders tests the replacePlaceholders function.
@@ -221,22 +235,563 @@ func TestReplacePlaceholdersNoPlaceholders(t *testing.T) {
 	}
 }
 
+// TestReplacePlaceholdersMultibyte tests the byte-wise placeholder pass keeps
+// multi-byte text and expands slices in place.
+func TestReplacePlaceholdersMultibyte(t *testing.T) {
+	query := "SELECT * FROM usuários WHERE nome = ? AND idade IN (?) AND cidade = 'São Paulo' AND id > ?"
+	args := []interface{}{"João", []int{1, 2}, 3, "extra"}
+	expectedQuery := "SELECT * FROM usuários WHERE nome = $1 AND idade IN ($2, $3) AND cidade = 'São Paulo' AND id > $4"
+	expectedArgs := []interface{}{"João", 1, 2, 3, "extra"}
+
+	resultQuery, resultArgs := replacePlaceholders(query, args)
+	if resultQuery != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, resultQuery)
+	}
+	if !reflect.DeepEqual(resultArgs, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, resultArgs)
+	}
+}
+
+// TestGenerateSQLPooled tests the pooled generation matches GenerateSQL across
+// reuses of the buffers by queries of different shapes.
+func TestGenerateSQLPooled(t *testing.T) {
+	for _, options := range [][]Option{
+		{WithFilter("id", OpIn, 1, 2, 3), WithSort([]string{"name"}, []string{"true"})},
+		{WithSearch("john"), WithSearchFields([]string{"name", "email"})},
+		{WithPage(3), WithItemsPerPage(5)},
+		{WithFilter("name", OpEq, "john"), WithOr(), WithFilter("age", OpGt, 30), WithFilter("email", OpLike, "x"), WithSearch("jo"), WithSearchFields([]string{"name"})},
+		{WithDefaultFilters(WithFilter("age", OpGte, 18)), WithFilter("id", OpNotIn, []int{1, 2})},
+	} {
+		p, err := NewPaginator(append([]Option{WithTable("users"), WithStruct(User{})}, options...)...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expectedQuery, expectedArgs := p.GenerateSQL()
+		for i := 0; i < 2; i++ {
+			query, args, release := p.GenerateSQLPooled()
+			if query != expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+			}
+			release()
+		}
+	}
+}
+
+// TestWhereFastPath tests skipping the WHERE assembly only without conditions.
+func TestWhereFastPath(t *testing.T) {
+	query, args := mustGenerateSQL(t, []Option{WithTable("users"), WithStruct(User{})})
+	expectedQuery := "SELECT * FROM users LIMIT $1 OFFSET $2"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{10, 0}) {
+		t.Errorf("Expected args: [10 0]\nGot: %v", args)
+	}
+
+	for name, option := range map[string]Option{
+		"partition":         WithPartitionFilter("users.region", "eu"),
+		"row security":      WithRowSecurity("users.tenant_id = ?", 1),
+		"soft delete":       WithSoftDelete("users.deleted_at"),
+		"default filters":   WithDefaultFilters(WithFilter("age", OpGte, 18)),
+		"search":            func(params *QueryParams) { params.Search, params.SearchFields = "john", []string{"name"} },
+		"search condition":  WithSearchField("name", SearchPrefix, "jo"),
+		"multi search":      WithSearchAnyOf([]string{"jo"}, "name"),
+		"weighted search":   WithSearchWeighted("john", map[string]float64{"name": 1}),
+		"filter":            WithFilter("age", OpGte, 18),
+		"column comparison": WithColumnComparison("age", "<", "id"),
+		"where clause":      WithWhereClause("users.age > ?", 18),
+	} {
+		t.Run(name, func(t *testing.T) {
+			query, _ := mustGenerateSQL(t, []Option{WithTable("users"), WithStruct(User{}), option})
+			if !strings.Contains(query, " WHERE ") {
+				t.Errorf("Expected a WHERE clause, got: %s", query)
+			}
+		})
+	}
+}
+
+// TestWithSearchAllExcept tests searching every tagged text field but the excluded ones.
+func TestWithSearchAllExcept(t *testing.T) {
+	type Status string
+	type Contact struct {
+		ID       int            `json:"id" paginate:"contacts.id"`
+		Name     string         `json:"name" paginate:"contacts.name"`
+		Nickname *string        `json:"nickname" paginate:"contacts.nickname"`
+		Status   Status         `json:"status" paginate:"contacts.status"`
+		Phone    sql.NullString `json:"phone" paginate:"contacts.phone"`
+		Notes    string         `json:"notes" paginate:"contacts.notes"`
+		Email    string         `json:"email" paginate:"contacts.email"`
+		Internal string         `json:"internal"`
+	}
+
+	query, args := mustGenerateSQL(t, []Option{
+		WithTable("contacts"),
+		WithStruct(Contact{}),
+		WithSearchAllExcept("jo", "notes", "email"),
+	})
+	expectedQuery := "SELECT * FROM contacts WHERE (contacts.name::TEXT ILIKE $1 OR contacts.nickname::TEXT ILIKE $2 OR contacts.status::TEXT ILIKE $3 OR contacts.phone::TEXT ILIKE $4) LIMIT $5 OFFSET $6"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%jo%", "%jo%", "%jo%", "%jo%", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	if _, err := NewPaginator(WithTable("contacts"), WithSearchAllExcept("jo")); err == nil {
+		t.Error("Expected error without a struct")
+	}
+}
+
+// TestWithAnsiLimit tests the ANSI OFFSET and FETCH FIRST clause.
+func TestWithAnsiLimit(t *testing.T) {
+	query, args := mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithAnsiLimit(),
+		WithSort([]string{"id"}, []string{"false"}),
+		WithPage(3),
+		WithItemsPerPage(20),
+	})
+	expectedQuery := "SELECT * FROM users ORDER BY users.id ASC OFFSET $1 ROWS FETCH FIRST $2 ROWS ONLY"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{40, 20}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	query, _ = mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithAnsiLimit(),
+		WithNoOffset(true),
+		WithSort([]string{"id"}, []string{"false"}),
+	})
+	if !strings.HasSuffix(query, "ORDER BY users.id ASC FETCH FIRST $1 ROWS ONLY") {
+		t.Errorf("Expected FETCH FIRST without OFFSET, got: %s", query)
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithAnsiLimit()); err == nil {
+		t.Error("Expected error for an ANSI limit without a sort")
+	}
+
+	for _, dialect := range []Dialect{DialectMySQL, DialectSQLite} {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSort([]string{"id"}, []string{"false"}), WithAnsiLimit(), WithDialect(dialect)); err == nil {
+			t.Errorf("Expected error for an ANSI limit on %s", dialect)
+		}
+	}
+}
+
+// TestWithJoinIfUsed tests emitting conditional joins only when their alias is referenced.
+func TestWithJoinIfUsed(t *testing.T) {
+	type Member struct {
+		ID   int    `json:"id" paginate:"users.id"`
+		Name string `json:"name" paginate:"users.name"`
+		Bio  string `json:"bio" paginate:"p.bio"`
+		Team string `json:"team" paginate:"tp.name"`
+	}
+	base := []Option{
+		WithTable("users"),
+		WithStruct(Member{}),
+		WithJoinIfUsed("profiles p", "p.user_id = users.id"),
+		WithJoinIfUsed("teams tp", "tp.id = users.team_id"),
+	}
+
+	tests := []struct {
+		name               string
+		options            []Option
+		expectedQuery      string
+		expectedCountQuery string
+	}{
+		{
+			"unused",
+			[]Option{WithFilter("name", OpEq, "john")},
+			"SELECT * FROM users WHERE users.name = $1 LIMIT $2 OFFSET $3",
+			"SELECT COUNT(users.id) FROM users WHERE users.name = $1",
+		},
+		{
+			"filter",
+			[]Option{WithFilter("bio", OpEq, "dev")},
+			"SELECT * FROM users LEFT JOIN profiles p ON p.user_id = users.id WHERE p.bio = $1 LIMIT $2 OFFSET $3",
+			"SELECT COUNT(users.id) FROM users LEFT JOIN profiles p ON p.user_id = users.id WHERE p.bio = $1",
+		},
+		{
+			"sort",
+			[]Option{WithSort([]string{"team"}, []string{"false"})},
+			"SELECT * FROM users LEFT JOIN teams tp ON tp.id = users.team_id ORDER BY tp.name ASC LIMIT $1 OFFSET $2",
+			"SELECT COUNT(users.id) FROM users",
+		},
+		{
+			"select",
+			[]Option{WithColumn("users.name"), WithColumn("p.avatar")},
+			"SELECT users.name, p.avatar FROM users LEFT JOIN profiles p ON p.user_id = users.id LIMIT $1 OFFSET $2",
+			"SELECT COUNT(users.id) FROM users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(append(base[:len(base):len(base)], tt.options...)...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			query, _ := p.GenerateSQL()
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			countQuery, _ := p.GenerateCountQuery()
+			if countQuery != tt.expectedCountQuery {
+				t.Errorf("Expected count query:\n%s\nGot:\n%s", tt.expectedCountQuery, countQuery)
+			}
+		})
+	}
+
+	if !referencesAlias("tp.name = ?", "tp") || referencesAlias("stp.name = ?", "tp") || referencesAlias("x.tp.name", "tp") {
+		t.Error("Expected alias references to respect identifier boundaries")
+	}
+}
+
+// TestWithEstimateThreshold tests choosing the exact count below the estimate threshold.
+func TestWithEstimateThreshold(t *testing.T) {
+	estimate := "SELECT count_estimate('SELECT 1 FROM users WHERE users.age >= ' || quote_nullable($1::TEXT))"
+	tests := []struct {
+		name          string
+		threshold     int
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{"estimate only", 0, estimate + ";", []interface{}{18}},
+		{"exact below threshold", 1000, "SELECT CASE WHEN estimated.count < 1000 THEN (SELECT COUNT(users.id) FROM users WHERE users.age >= $2) ELSE estimated.count END FROM (" + estimate + " AS count) AS estimated;", []interface{}{18, 18}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithVacuum(true),
+				WithEstimateThreshold(tt.threshold),
+				WithFilter("age", OpGte, 18),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			query, args := p.GenerateCountQuery()
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", tt.expectedArgs, args)
+			}
+
+			// Each placeholder is bound once, so the integer filter of the
+			// exact count isn't typed as the text of the estimate.
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer db.Close()
+			expectedArgs := make([]driver.Value, len(tt.expectedArgs))
+			for i, arg := range tt.expectedArgs {
+				expectedArgs[i] = arg
+			}
+			mock.ExpectQuery(tt.expectedQuery).WithArgs(expectedArgs...).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+			var count int
+			if err := db.QueryRow(query, args...).Scan(&count); err != nil || count != 42 {
+				t.Errorf("Expected count 42, got: %d (%v)", count, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+// TestWithRawSelect tests a raw select list numbered before the filter arguments.
+func TestWithRawSelect(t *testing.T) {
+	query, args := mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.name"),
+		WithRawSelect("DISTINCT ON (users.email) users.*, CASE WHEN users.age >= ? THEN 'adult' ELSE 'minor' END AS bracket", 18),
+		WithFilter("name", OpEq, "john"),
+		WithSort([]string{"email"}, []string{"false"}),
+	})
+	expectedQuery := "SELECT DISTINCT ON (users.email) users.*, CASE WHEN users.age >= $1 THEN 'adult' ELSE 'minor' END AS bracket FROM users WHERE users.name = $2 ORDER BY users.email ASC LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithSelectFlag tests computed flag columns numbered before the filter arguments.
+func TestWithSelectFlag(t *testing.T) {
+	options := []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSelectFlag("is_adult", "users.age >= ?", 18),
+		WithSelectFlag("has_email", "users.email IS NOT NULL"),
+		WithFilter("name", OpEq, "john"),
+	}
+	query, args := mustGenerateSQL(t, options)
+	expectedQuery := "SELECT *, (users.age >= $1) AS is_adult, (users.email IS NOT NULL) AS has_email FROM users WHERE users.name = $2 LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	query, _ = mustGenerateSQL(t, append(options, WithColumn("users.id")))
+	if !strings.HasPrefix(query, "SELECT users.id, (users.age >= $1) AS is_adult, ") {
+		t.Errorf("Expected the flags after the custom columns, got: %s", query)
+	}
+
+	p, err := NewPaginator(options...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	parts := p.Parts()
+	expectedColumns := []string{"*, (users.age >= ?) AS is_adult, (users.email IS NOT NULL) AS has_email"}
+	if !reflect.DeepEqual(parts.Columns, expectedColumns) || !reflect.DeepEqual(parts.ColumnArgs, []interface{}{18}) {
+		t.Errorf("Expected columns %v with args [18], got: %v %v", expectedColumns, parts.Columns, parts.ColumnArgs)
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithSelectFlag("in stock", "stock > 0")); err == nil {
+		t.Error("Expected error for an invalid flag alias")
+	}
+}
+
+// TestGenerateWithRedaction tests redacting the sensitive and long arguments.
+func TestGenerateWithRedaction(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRedaction(12, "email"),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithSearchField("email", SearchPrefix, "jo"),
+		WithFilter("age", OpGte, 18),
+		WithFilter("email", OpIn, "a@x.com", "b@x.com"),
+		WithFilter("name", OpEq, "a very long name"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery, expectedArgs := p.GenerateSQL()
+	query, args, redactedArgs := p.GenerateWithRedaction()
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	expectedRedactedArgs := []interface{}{"%john%", "***", 18, "***", "***", "***", 10, 0}
+	if !reflect.DeepEqual(redactedArgs, expectedRedactedArgs) {
+		t.Errorf("Expected redacted args: %v\nGot: %v", expectedRedactedArgs, redactedArgs)
+	}
+
+	// Values are redacted by the field they are bound to in every clause.
+	p, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRedaction(0, "email"),
+		WithDefaultFilters(WithFilter("email", OpNeq, "root@x.com")),
+		WithSearch("jo"),
+		WithSearchFields([]string{"name", "email"}),
+		WithOrFilter(WithFilter("email", OpEq, "or@x.com")),
+		WithSearchAnyOf([]string{"ann"}, "name", "email"),
+		WithSearchWeighted("weighted", map[string]float64{"email": 1}),
+		WithCursor("email", "last@x.com", "ASC"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, args, redactedArgs = p.GenerateWithRedaction()
+	expectedRedactedArgs = []interface{}{"***", "***", "%jo%", "***", "***", "%ann%", "***", "***", "***", 10}
+	if !reflect.DeepEqual(redactedArgs, expectedRedactedArgs) {
+		t.Errorf("Expected redacted args: %v\nGot: %v (from %v)", expectedRedactedArgs, redactedArgs, args)
+	}
+
+	// Nil values keep their predicate, so one-sided ranges don't shift the mask.
+	p, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRedaction(0, "age", "email"),
+		WithFilter("age", OpNotBetween, nil, 65),
+		WithFilter("id", OpNotBetween, 100, nil),
+		WithFilter("name", OpEq, "john"),
+		WithFilter("email", OpEq, "john@x.com"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, args, redactedArgs = p.GenerateWithRedaction()
+	expectedRedactedArgs = []interface{}{"***", 100, "john", "***", 10, 0}
+	if !reflect.DeepEqual(redactedArgs, expectedRedactedArgs) {
+		t.Errorf("Expected redacted args: %v\nGot: %v (from %v)", expectedRedactedArgs, redactedArgs, args)
+	}
+}
+
+// TestResetFilters tests clearing the request state while keeping the table and model.
+func TestResetFilters(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithSchema("public"),
+		WithStruct(User{}),
+		WithJoin("INNER JOIN orders ON users.id = orders.user_id"),
+		WithDialect(DialectMySQL),
+		WithSoftDelete("users.deleted_at"),
+		WithPage(3),
+		WithItemsPerPage(50),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithSort([]string{"name"}, []string{"true"}),
+		WithFilter("age", OpGte, 18),
+		WithWhereClause("users.age < ?", 65),
+		WithDataOnlyWhere("users.active = ?", true),
+		WithCountOnlyWhere("users.verified = ?", true),
+		WithFields("id,name"),
+		WithInclude("orders"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p.ResetFilters()
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM public.users INNER JOIN orders ON users.id = orders.user_id WHERE users.deleted_at IS NULL LIMIT ? OFFSET ?"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	if query, _ := p.GenerateCountQuery(); strings.Contains(query, "verified") {
+		t.Errorf("Expected the count-only clause to be reset, got: %s", query)
+	}
+	if p.Includes != nil {
+		t.Errorf("Expected the includes to be reset, got: %v", p.Includes)
+	}
+
+	if err := p.Apply(WithFilter("name", OpEq, "jane")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "WHERE users.deleted_at IS NULL AND users.name = ?") {
+		t.Errorf("Expected the new filter after the reset, got: %s", query)
+	}
+
+	// Apply validates like NewPaginator and leaves the params unchanged on error.
+	if err := p.Apply(WithFilter("age", "approx", 18), WithPage(5)); err == nil {
+		t.Error("Expected error for an unsupported operator")
+	}
+	if len(p.Filters) != 1 || p.Page != 1 {
+		t.Errorf("Expected the params to be unchanged, got filters %v on page %d", p.Filters, p.Page)
+	}
+}
+
+// TestGenerateNamedSQL tests the named placeholders and arguments for sqlx.
+func TestGenerateNamedSQL(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithFilter("age", OpBetween, 18, 65),
+		WithFilter("name", OpEq, "John"),
+		WithOr(),
+		WithFilter("name", OpEq, "Jane"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateNamedSQL()
+	expectedQuery := "SELECT * FROM users WHERE (users.name::::TEXT ILIKE :arg1 OR users.email::::TEXT ILIKE :arg2) AND users.age BETWEEN :arg3 AND :arg4 AND (users.name = :arg5 OR users.name = :arg6) LIMIT :arg7 OFFSET :arg8"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := map[string]interface{}{
+		"arg1": "%john%", "arg2": "%john%",
+		"arg3": 18, "arg4": 65,
+		"arg5": "John", "arg6": "Jane",
+		"arg7": 10, "arg8": 0,
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	if positionalQuery, _ := p.GenerateSQL(); !strings.Contains(positionalQuery, "$1") {
+		t.Errorf("Expected the positional placeholders to be unaffected, got: %s", positionalQuery)
+	}
+}
+
 // TestGetFieldName tests the getFieldName function.
 func TestGetFieldName(t *testing.T) {
 	s := User{}
-	fieldName := getFieldName("name", "json", "paginate", s)
+	fieldName, err := getFieldName("name", "json", "paginate", s)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 	expected := "users.name"
 	if fieldName != expected {
 		t.Errorf("Expected field name: %s\nGot: %s", expected, fieldName)
 	}
 
 	// Test non-existent field.
-	fieldName = getFieldName("nonexistent", "json", "paginate", s)
+	fieldName, _ = getFieldName("nonexistent", "json", "paginate", s)
 	if fieldName != "" {
 		t.Errorf("Expected empty field name, got: %s", fieldName)
 	}
 }
 
+// findFieldName is the uncached reflection over the struct fields that
+// getFieldName caches per type.
+func findFieldName(tag, key, keyTarget string, rt reflect.Type) string {
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tagValue := strings.Split(field.Tag.Get(key), ",")[0]
+		if tagValue == tag {
+			return field.Tag.Get(keyTarget)
+		}
+	}
+	return ""
+}
+
+// TestGetFieldNameCache tests the cached resolution matches the uncached one.
+func TestGetFieldNameCache(t *testing.T) {
+	type Untagged struct {
+		Name  string `paginate:"untagged.name"`
+		Email string
+	}
+
+	for _, model := range []interface{}{User{}, &User{}, Post{}, Untagged{}} {
+		rt := reflect.Indirect(reflect.ValueOf(model)).Type()
+		for _, tag := range []string{"id", "name", "email", "age", "title", "", "nonexistent"} {
+			for i := 0; i < 2; i++ {
+				cached, _ := getFieldName(tag, "json", "paginate", model)
+				if uncached := findFieldName(tag, "json", "paginate", rt); cached != uncached {
+					t.Errorf("Expected %T field %q to resolve to %q, got %q", model, tag, uncached, cached)
+				}
+			}
+		}
+	}
+}
+
 // TestWithSortInvalidDirections tests WithSort with mismatched directions.
 func TestWithSortInvalidDirections(t *testing.T) {
 	p, err := NewPaginator(
@@ -451,7 +1006,7 @@ func TestFullComplexPaginator(t *testing.T) {
 	query, args := p.GenerateSQL()
 
 	// Expected SQL query.
-	expectedQuery := "SELECT u.id, u.name, u.email, r.name AS role_name, SUM(o.total) AS total_spent FROM public.users INNER JOIN roles r ON u.role_id = r.id LEFT JOIN orders o ON u.id = o.user_id WHERE (u.name::TEXT ILIKE $1 OR u.email::TEXT ILIKE $2) AND u.age BETWEEN $3 AND $4 AND u.is_active = $5 AND o.status IN ($6) ORDER BY u.name ASC LIMIT $7 OFFSET $8"
+	expectedQuery := "SELECT u.id, u.name, u.email, r.name AS role_name, SUM(o.total) AS total_spent FROM public.users INNER JOIN roles r ON u.role_id = r.id LEFT JOIN orders o ON u.id = o.user_id WHERE (u.name::TEXT ILIKE $1 OR u.email::TEXT ILIKE $2) AND u.age BETWEEN $3 AND $4 AND u.is_active = $5 AND o.status IN ($6, $7) ORDER BY u.name ASC LIMIT $8 OFFSET $9"
 
 	// Check if the generated query matches the expected query.
 	if query != expectedQuery {
@@ -465,9 +1020,10 @@ func TestFullComplexPaginator(t *testing.T) {
 		mapArgs["min_age"], // u.age BETWEEN ?
 		mapArgs["max_age"],
 		mapArgs["active_only"], // u.is_active = ?
-		mapArgs["statuses"],    // o.status IN (?)
-		15,                     // LIMIT
-		30,                     // OFFSET (page 3 with 15 items per page)
+		"completed",            // o.status IN (?) expanded from the slice
+		"shipped",
+		15, // LIMIT
+		30, // OFFSET (page 3 with 15 items per page)
 	}
 
 	// Check if the generated arguments match the expected arguments.
@@ -479,7 +1035,7 @@ func TestFullComplexPaginator(t *testing.T) {
 	countQuery, countArgs := p.GenerateCountQuery()
 
 	// Expected count query.
-	expectedCountQuery := "SELECT COUNT(u.id) FROM public.users INNER JOIN roles r ON u.role_id = r.id LEFT JOIN orders o ON u.id = o.user_id WHERE (u.name::TEXT ILIKE $1 OR u.email::TEXT ILIKE $2) AND u.age BETWEEN $3 AND $4 AND u.is_active = $5 AND o.status IN ($6)"
+	expectedCountQuery := "SELECT COUNT(u.id) FROM public.users INNER JOIN roles r ON u.role_id = r.id LEFT JOIN orders o ON u.id = o.user_id WHERE (u.name::TEXT ILIKE $1 OR u.email::TEXT ILIKE $2) AND u.age BETWEEN $3 AND $4 AND u.is_active = $5 AND o.status IN ($6, $7)"
 
 	// Check if the generated count query matches the expected count query.
 	if countQuery != expectedCountQuery {
@@ -487,7 +1043,1983 @@ func TestFullComplexPaginator(t *testing.T) {
 	}
 
 	// Check if the generated count arguments match the expected arguments.
-	if !reflect.DeepEqual(countArgs, expectedArgs[:6]) {
-		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:6], countArgs)
+	if !reflect.DeepEqual(countArgs, expectedArgs[:7]) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:7], countArgs)
+	}
+}
+
+// TestWithAutoColumns tests column resolution for a model without paginate tags.
+func TestWithAutoColumns(t *testing.T) {
+	type Event struct {
+		ID        int
+		Title     string `json:"title"`
+		CreatedAt string
+	}
+
+	p, err := NewPaginator(
+		WithTable("events"),
+		WithStruct(Event{}),
+		WithAutoColumns(),
+		WithSearch("launch"),
+		WithSearchFields([]string{"title"}),
+		WithSort([]string{"created_at"}, []string{"true"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM events WHERE (title::TEXT ILIKE $1) ORDER BY created_at DESC LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	countQuery, _ := p.GenerateCountQuery()
+	expectedCountQuery := "SELECT COUNT(id) FROM events WHERE (title::TEXT ILIKE $1)"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+
+	// Without the option the tagless fields don't resolve.
+	p, err = NewPaginator(
+		WithTable("events"),
+		WithStruct(Event{}),
+		WithSort([]string{"created_at"}, []string{"true"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ = p.GenerateSQL()
+	if strings.Contains(query, "ORDER BY") {
+		t.Errorf("Expected no ORDER BY clause without auto columns, got: %s", query)
+	}
+}
+
+// TestToSnakeCase tests the toSnakeCase function.
+func TestToSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"ID":             "id",
+		"Name":           "name",
+		"CreatedAt":      "created_at",
+		"UserID":         "user_id",
+		"XMLHttpRequest": "xml_http_request",
+		"APIKey":         "api_key",
+		"OAuthToken":     "o_auth_token",
+		"HTTPStatusCode": "http_status_code",
+	}
+	for input, expected := range cases {
+		if result := toSnakeCase(input); result != expected {
+			t.Errorf("toSnakeCase(%q): expected %q, got %q", input, expected, result)
+		}
+	}
+}
+
+// TestWithRejectUnknownFields tests rejecting fields that don't resolve to a column.
+func TestWithRejectUnknownFields(t *testing.T) {
+	// Unknown fields are dropped by default.
+	_, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"nonexistent"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRejectUnknownFields(),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "nonexistent"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "nonexistent"`) {
+		t.Errorf("Expected unknown search field error, got: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRejectUnknownFields(),
+		WithSort([]string{"salary"}, []string{"true"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "salary"`) {
+		t.Errorf("Expected unknown sort field error, got: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRejectUnknownFields(),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithSort([]string{"age"}, []string{"true"}),
+	)
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestWithAllowColumns tests rejecting fields missing from the allowlist.
+func TestWithAllowColumns(t *testing.T) {
+	// An unlisted sort field errors instead of being dropped from the ORDER BY.
+	_, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithAllowColumns("name", "users.age"),
+		WithSort([]string{"email"}, []string{"false"}),
+	)
+	if err == nil || !strings.Contains(err.Error(), `field "email" is not allowed`) {
+		t.Errorf("Expected unlisted sort field error, got: %v", err)
+	}
+
+	for _, option := range []Option{
+		WithSearchFields([]string{"name", "email"}),
+		WithSearchField("email", SearchPrefix, "jo"),
+		WithFilter("id", OpEq, 1),
+		WithCursor("id", 1, "ASC"),
+		WithFields("name,email"),
+		WithOrFilter(WithFilter("email", OpEq, "jo@example.com")),
+		WithSearchWeighted("jo", map[string]float64{"name": 1, "email": 0.4}),
+	} {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithAllowColumns("name", "users.age"), WithSearch("jo"), option); err == nil || !strings.Contains(err.Error(), "is not allowed") {
+			t.Errorf("Expected unlisted field error, got: %v", err)
+		}
+	}
+
+	query, _ := mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithAllowColumns("name", "users.age"),
+		WithSearch("jo"),
+		WithSearchFields([]string{"name"}),
+		WithFilter("age", OpGte, 18),
+		WithSort([]string{"age"}, []string{"true"}),
+	})
+	expectedQuery := "SELECT * FROM users WHERE (users.name::TEXT ILIKE $1) AND users.age >= $2 ORDER BY users.age DESC LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestWithValidateSearchFields tests dropping the non-text search fields.
+func TestWithValidateSearchFields(t *testing.T) {
+	searchFields := []string{"name", "age", "email"}
+	options := []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("42"),
+		WithSearchFields(searchFields),
+		WithSearchField("id", SearchExact, "42"),
+	}
+
+	query, _ := mustGenerateSQL(t, options)
+	if !strings.Contains(query, "users.age::TEXT") {
+		t.Errorf("Expected the int field searched without validation, got: %s", query)
+	}
+
+	query, args := mustGenerateSQL(t, append(options, WithValidateSearchFields()))
+	expectedQuery := "SELECT * FROM users WHERE (users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2) LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%42%", "%42%", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	if !reflect.DeepEqual(searchFields, []string{"name", "age", "email"}) {
+		t.Errorf("Expected the search fields option left unchanged, got: %v", searchFields)
+	}
+
+	// Named string types, sql.NullString and string pointers are text.
+	type Code string
+	type Account struct {
+		Code     Code           `json:"code" paginate:"accounts.code"`
+		Phone    sql.NullString `json:"phone" paginate:"accounts.phone"`
+		Nickname *string        `json:"nickname" paginate:"accounts.nickname"`
+		Balance  sql.NullInt64  `json:"balance" paginate:"accounts.balance"`
+	}
+	query, _ = mustGenerateSQL(t, []Option{
+		WithTable("accounts"),
+		WithStruct(Account{}),
+		WithSearch("42"),
+		WithSearchFields([]string{"code", "phone", "nickname", "balance"}),
+		WithValidateSearchFields(),
+	})
+	expectedQuery = "SELECT * FROM accounts WHERE (accounts.code::TEXT ILIKE $1 OR accounts.phone::TEXT ILIKE $2 OR accounts.nickname::TEXT ILIKE $3) LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestOffsetOverflow tests the offset computation with very large and negative inputs.
+func TestOffsetOverflow(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithPage(math.MaxInt),
+		WithItemsPerPage(math.MaxInt),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, args := p.GenerateSQL()
+	if args[1] != math.MaxInt {
+		t.Errorf("Expected offset clamped to %d, got: %v", math.MaxInt, args[1])
+	}
+
+	WithMaxOffset(10000)(p)
+	_, args = p.GenerateSQL()
+	if args[1] != 10000 {
+		t.Errorf("Expected offset clamped to 10000, got: %v", args[1])
+	}
+
+	WithPage(3)(p)
+	WithItemsPerPage(20)(p)
+	_, args = p.GenerateSQL()
+	if args[1] != 40 {
+		t.Errorf("Expected offset 40, got: %v", args[1])
+	}
+
+	WithPage(-5)(p)
+	_, args = p.GenerateSQL()
+	if args[1] != 0 {
+		t.Errorf("Expected offset clamped to 0, got: %v", args[1])
+	}
+}
+
+// TestValidatePage tests the ValidatePage method.
+func TestValidatePage(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithPage(2),
+		WithItemsPerPage(10),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// In range.
+	if err := p.ValidatePage(35); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Exactly the last page.
+	WithPage(4)(p)
+	if err := p.ValidatePage(35); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	// Beyond the last page.
+	WithPage(5)(p)
+	err = p.ValidatePage(35)
+	if err == nil || !strings.Contains(err.Error(), "last page is 4") {
+		t.Errorf("Expected out of range error, got: %v", err)
+	}
+
+	// The first page is always valid, even without items.
+	WithPage(1)(p)
+	if err := p.ValidatePage(0); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+// TestCacheKey tests the CacheKey method.
+func TestCacheKey(t *testing.T) {
+	options := []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithPage(2),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithWhereClause("age > ?", 30),
+	}
+
+	p1, err := NewPaginator(options...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	p2, err := NewPaginator(options...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if p1.CacheKey() != p2.CacheKey() {
+		t.Errorf("Expected identical params to produce identical keys")
+	}
+	if len(p1.CacheKey()) != 64 {
+		t.Errorf("Expected a sha256 hex key, got: %s", p1.CacheKey())
+	}
+
+	p3, err := NewPaginator(append(options, WithWhereClause("age < ?", 60))...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p1.CacheKey() == p3.CacheKey() {
+		t.Errorf("Expected different filters to produce different keys")
+	}
+
+	// Same SQL shape but a different argument value.
+	p4, err := NewPaginator(append(options[:5:5], WithWhereClause("age > ?", 31))...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if p1.CacheKey() == p4.CacheKey() {
+		t.Errorf("Expected different arguments to produce different keys")
+	}
+}
+
+// TestWithMetricsHook tests the metrics reported to the hook.
+func TestWithMetricsHook(t *testing.T) {
+	var metrics QueryMetrics
+	calls := 0
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithItemsPerPage(25),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email", "nonexistent"}),
+		WithWhereClause("age > ?", 30),
+		WithWhereClause("age < ?", 60),
+		WithOrFilter(WithFilter("age", OpGt, 65)),
+		WithJoin("INNER JOIN orders ON users.id = orders.user_id"),
+		WithSort([]string{"name"}, []string{"false"}),
+		WithMetricsHook(func(m QueryMetrics) {
+			metrics = m
+			calls++
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p.GenerateSQL()
+
+	expected := QueryMetrics{
+		Filters: map[string]int{"search": 2, "gt": 1, "where": 2},
+		Joins:   1,
+		Sorted:  true,
+		Limit:   25,
+	}
+	if calls != 1 {
+		t.Errorf("Expected the hook to be called once, got: %d", calls)
+	}
+	if !reflect.DeepEqual(metrics, expected) {
+		t.Errorf("Expected metrics: %+v\nGot: %+v", expected, metrics)
+	}
+
+	p.CacheKey()
+	p.GenerateExplainSQL()
+	if calls != 1 {
+		t.Errorf("Expected no metrics for the cache key and explain queries, got %d calls", calls)
+	}
+}
+
+// TestWithNullsOrdering tests the NULLS directive appended to each sort column.
+func TestWithNullsOrdering(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSort([]string{"name", "age"}, []string{"false", "true"}),
+		WithNullsOrdering(NullsLast),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := p.GenerateSQL()
+	if !strings.Contains(query, "ORDER BY users.name ASC NULLS LAST, users.age DESC NULLS LAST") {
+		t.Errorf("Expected NULLS LAST on each sort column, got: %s", query)
+	}
+
+	WithNullsOrdering(NullsFirst)(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "ORDER BY users.name ASC NULLS FIRST, users.age DESC NULLS FIRST") {
+		t.Errorf("Expected NULLS FIRST on each sort column, got: %s", query)
+	}
+
+	WithNullsOrdering(NullsDefault)(p)
+	query, _ = p.GenerateSQL()
+	if strings.Contains(query, "NULLS") {
+		t.Errorf("Expected no NULLS directive by default, got: %s", query)
+	}
+
+	query, _ = mustGenerateSQL(t, []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithDialect(DialectMySQL),
+		WithSort([]string{"name"}, []string{"false"}),
+		WithOrderByNulls("age", "DESC", NullsFirst),
+		WithNullsOrdering(NullsLast),
+	})
+	expectedOrder := "ORDER BY CASE WHEN users.name IS NULL THEN 1 ELSE 0 END, users.name ASC, CASE WHEN users.age IS NULL THEN 0 ELSE 1 END, users.age DESC LIMIT"
+	if !strings.Contains(query, expectedOrder) {
+		t.Errorf("Expected %s, got: %s", expectedOrder, query)
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithNullsOrdering("LAST; DROP TABLE users --")); err == nil {
+		t.Error("Expected error for an invalid nulls ordering")
+	}
+}
+
+// TestWithOrderByNulls tests the per-column NULLS placement of the ORDER BY.
+func TestWithOrderByNulls(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedOrder string
+	}{
+		{
+			"after the sort columns",
+			[]Option{WithSort([]string{"name"}, []string{"false"}), WithOrderByNulls("age", "asc", NullsLast)},
+			"ORDER BY users.name ASC, users.age ASC NULLS LAST LIMIT",
+		},
+		{
+			"overrides the global placement",
+			[]Option{WithNullsOrdering(NullsLast), WithSort([]string{"name"}, []string{"true"}), WithOrderByNulls("email", "DESC", "first")},
+			"ORDER BY users.name DESC NULLS LAST, users.email DESC NULLS FIRST LIMIT",
+		},
+		{
+			"default without a placement",
+			[]Option{WithSort([]string{"name", "age"}, []string{"false", "true"})},
+			"ORDER BY users.name ASC, users.age DESC LIMIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := mustGenerateSQL(t, append([]Option{WithTable("users"), WithStruct(User{})}, tt.options...))
+			if !strings.Contains(query, tt.expectedOrder) {
+				t.Errorf("Expected %s, got: %s", tt.expectedOrder, query)
+			}
+		})
+	}
+
+	for _, option := range []Option{WithOrderByNulls("age", "ASC", "MIDDLE"), WithOrderByNulls("age", "up", NullsLast), WithOrderByNulls("age", "ASC", NullsDefault)} {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), option); err == nil {
+			t.Error("Expected error for an invalid nulls sort")
+		}
+	}
+}
+
+// TestWithSearchField tests search conditions with per-field modes.
+func TestWithSearchField(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearchField("email", SearchExact, "john@example.com"),
+		WithSearchField("name", SearchContains, "john"),
+		WithSearchField("name", SearchPrefix, "jo"),
+		WithSearchField("email", SearchSuffix, "@example.com"),
+		WithWhereClause("age > ?", 30),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE (users.email::TEXT = $1 OR users.name::TEXT ILIKE $2 OR users.name::TEXT ILIKE $3 OR users.email::TEXT ILIKE $4) AND age > $5 LIMIT $6 OFFSET $7"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	expectedArgs := []interface{}{"john@example.com", "%john%", "jo%", "%@example.com", 30, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestReplacePlaceholdersSliceExpansion tests expanding slice arguments into multiple placeholders.
+func TestReplacePlaceholdersSliceExpansion(t *testing.T) {
+	query := "SELECT * FROM users WHERE status IN (?) AND age > ? AND role IN (?) AND token = ?"
+	args := []interface{}{[]string{"active", "pending", "blocked"}, 30, []int{}, []byte("abc")}
+	expectedQuery := "SELECT * FROM users WHERE status IN ($1, $2, $3) AND age > $4 AND role IN (NULL) AND token = $5"
+	expectedArgs := []interface{}{"active", "pending", "blocked", 30, []byte("abc")}
+
+	resultQuery, resultArgs := replacePlaceholders(query, args)
+	if resultQuery != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, resultQuery)
+	}
+	if !reflect.DeepEqual(resultArgs, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, resultArgs)
+	}
+}
+
+// stringArray is a driver.Valuer slice like pq.StringArray.
+type stringArray []string
+
+// Value implements driver.Valuer.
+func (a stringArray) Value() (driver.Value, error) {
+	return "{" + strings.Join(a, ",") + "}", nil
+}
+
+// TestReplacePlaceholdersScalarSlices tests byte and driver.Valuer slices bound as a single argument.
+func TestReplacePlaceholdersScalarSlices(t *testing.T) {
+	document := json.RawMessage(`{"a":1}`)
+	tags := stringArray{"go", "sql"}
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithWhereClause("data @> ?", document),
+		WithWhereClause("tags && ?", tags),
+		WithFilter("name", OpEq, [4]byte{1, 2, 3, 4}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.name = $1 AND data @> $2 AND tags && $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{[4]byte{1, 2, 3, 4}, document, tags, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithWhereClauseSliceArg tests a custom IN clause with a slice argument.
+func TestWithWhereClauseSliceArg(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithWhereClause("users.email IN (?)", []string{"a@example.com", "b@example.com"}),
+		WithWhereClause("age > ?", 30),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.email IN ($1, $2) AND age > $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"a@example.com", "b@example.com", 30, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestWithMinimalParens tests dropping parentheses around single-condition groups.
+func TestWithMinimalParens(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithMinimalParens(),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithWhereClause("age > ?", 30),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := p.GenerateSQL()
+	if !strings.Contains(query, "WHERE users.name::TEXT ILIKE $1 AND age > $2") {
+		t.Errorf("Expected single search condition without parentheses, got: %s", query)
+	}
+
+	WithSearchFields([]string{"name", "email"})(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "WHERE (users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2) AND age > $3") {
+		t.Errorf("Expected multiple search conditions wrapped in parentheses, got: %s", query)
+	}
+}
+
+// TestWithSortJSON tests ordering by a key of a JSON column.
+func TestWithSortJSON(t *testing.T) {
+	type Profile struct {
+		ID       int    `json:"id" paginate:"profiles.id"`
+		Name     string `json:"name" paginate:"profiles.name"`
+		Settings string `json:"settings" paginate:"profiles.settings"`
+	}
+
+	p, err := NewPaginator(
+		WithTable("profiles"),
+		WithStruct(Profile{}),
+		WithSort([]string{"name"}, []string{"false"}),
+		WithSortJSON("settings", "theme", "desc"),
+		WithSortJSON("settings", "address.city", "ASC"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := p.GenerateSQL()
+	expectedOrder := "ORDER BY profiles.name ASC, profiles.settings->>'theme' DESC, profiles.settings->'address'->>'city' ASC"
+	if !strings.Contains(query, expectedOrder) {
+		t.Errorf("Expected %q, got: %s", expectedOrder, query)
+	}
+
+	dialects := map[Dialect]string{
+		DialectMySQL:     `ORDER BY profiles.settings->>'$."address"."city"' ASC`,
+		DialectSQLite:    `ORDER BY json_extract(profiles.settings, '$."address"."city"') ASC`,
+		DialectOracle:    `ORDER BY JSON_VALUE(profiles.settings, '$."address"."city"') ASC`,
+		DialectSQLServer: `ORDER BY JSON_VALUE(profiles.settings, '$."address"."city"') ASC`,
+	}
+	for dialect, expectedOrder := range dialects {
+		p, err := NewPaginator(
+			WithTable("profiles"),
+			WithStruct(Profile{}),
+			WithDialect(dialect),
+			WithSortJSON("settings", "address.city", "ASC"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		query, _ := p.GenerateSQL()
+		if !strings.Contains(query, expectedOrder) {
+			t.Errorf("Expected %s %q, got: %s", dialect, expectedOrder, query)
+		}
+	}
+
+	_, err = NewPaginator(
+		WithTable("profiles"),
+		WithStruct(Profile{}),
+		WithSortJSON("settings", "theme'; DROP TABLE profiles; --", "ASC"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid JSON path") {
+		t.Errorf("Expected invalid JSON path error, got: %v", err)
+	}
+
+	_, err = NewPaginator(
+		WithTable("profiles"),
+		WithStruct(Profile{}),
+		WithSortJSON("settings", "theme", "sideways"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "invalid sort direction") {
+		t.Errorf("Expected invalid sort direction error, got: %v", err)
+	}
+}
+
+// TestWithDefaultFilters tests filters that are always applied alongside the request filters.
+func TestWithDefaultFilters(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithDefaultFilters(
+			WithFilter("age", OpGte, 18),
+			WithWhereClause("users.deleted_at IS NULL"),
+		),
+		WithFilter("name", OpEq, "john"),
+		WithWhereClause("users.email LIKE ?", "%@example.com"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.age >= $1 AND users.deleted_at IS NULL AND users.name = $2 AND users.email LIKE $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "john", "%@example.com", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// Clearing the request filters keeps the defaults.
+	p.Filters = nil
+	p.WhereClauses = nil
+	p.WhereArgs = nil
+	countQuery, countArgs := p.GenerateCountQuery()
+	expectedCountQuery := "SELECT COUNT(users.id) FROM users WHERE users.age >= $1 AND users.deleted_at IS NULL"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+	if !reflect.DeepEqual(countArgs, []interface{}{18}) {
+		t.Errorf("Expected count args: [18]\nGot: %v", countArgs)
+	}
+}
+
+// TestWithFields tests selecting a sparse fieldset of model fields.
+func TestWithFields(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFields("id, name"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(p.Fields, []string{"id", "name"}) {
+		t.Errorf("Expected fields [id name], got: %v", p.Fields)
+	}
+
+	query, _ := p.GenerateSQL()
+	if !strings.HasPrefix(query, "SELECT users.id, users.name FROM users") {
+		t.Errorf("Expected sparse fieldset columns, got: %s", query)
+	}
+
+	_, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFields("id,password"),
+	)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "password" in fields`) {
+		t.Errorf("Expected unknown field error, got: %v", err)
+	}
+
+	// Fields the guard rejects never fall back to every column.
+	guard := WithFieldGuard(func(field, op string) bool { return op != "select" || field == "name" })
+	_, err = NewPaginator(WithTable("users"), WithStruct(User{}), WithFields("id,email"), guard)
+	if err == nil || !strings.Contains(err.Error(), "rejected by the field guard") {
+		t.Errorf("Expected rejected fields error, got: %v", err)
+	}
+	p, err = NewPaginator(WithTable("users"), WithStruct(User{}), WithFields("name"), guard)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	WithFields("id,email")(p)
+	query, _ = p.GenerateSQL()
+	if !strings.HasPrefix(query, "SELECT NULL FROM users") {
+		t.Errorf("Expected no column for rejected fields, got: %s", query)
+	}
+}
+
+// TestWithSearchAnyOf tests matching any of several terms in any of several fields.
+func TestWithSearchAnyOf(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearchAnyOf([]string{"john", "doe"}, "name", "email", "nonexistent"),
+		WithWhereClause("age > ?", 30),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE (users.name::TEXT ILIKE $1 OR users.name::TEXT ILIKE $2 OR users.email::TEXT ILIKE $3 OR users.email::TEXT ILIKE $4) AND age > $5 LIMIT $6 OFFSET $7"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	expectedArgs := []interface{}{"%john%", "%doe%", "%john%", "%doe%", 30, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	conditions := p.Conditions()
+	if len(conditions) != 3 || conditions[0].Op != "search_any" || conditions[1].Field != "email" {
+		t.Errorf("Expected search_any conditions for name and email, got: %+v", conditions)
+	}
+}
+
+// TestWithQuotedTable tests quoting the schema and table in the FROM clause.
+func TestWithQuotedTable(t *testing.T) {
+	cases := []struct {
+		options []Option
+		from    string
+	}{
+		{[]Option{WithTable("users")}, `FROM "users" WHERE`},
+		{[]Option{WithSchema("public"), WithTable("users")}, `FROM "public"."users" WHERE`},
+		{[]Option{WithSchema("public"), WithTable("users u")}, `FROM "public"."users" u WHERE`},
+		{[]Option{WithSchema("sales"), WithTable("orders AS o")}, `FROM "sales"."orders" AS o WHERE`},
+		{[]Option{WithSchema("public"), WithTable(`we"ird`)}, `FROM "public"."we""ird" WHERE`},
+		{[]Option{WithSchema("public"), WithTable("users u"), WithDialect(DialectMySQL)}, "FROM `public`.`users` u WHERE"},
+	}
+	for _, c := range cases {
+		options := append([]Option{WithStruct(User{}), WithQuotedTable(), WithFilter("age", OpGt, 30)}, c.options...)
+		p, err := NewPaginator(options...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		query, _ := p.GenerateSQL()
+		if !strings.Contains(query, c.from) {
+			t.Errorf("Expected %s, got: %s", c.from, query)
+		}
+		countQuery, _ := p.GenerateCountQuery()
+		if !strings.Contains(countQuery, c.from) {
+			t.Errorf("Expected %s in count query, got: %s", c.from, countQuery)
+		}
+	}
+}
+
+// TestSchemaQualifiedTable tests a dotted table with and without the schema option.
+func TestSchemaQualifiedTable(t *testing.T) {
+	cases := []struct {
+		options []Option
+		from    string
+	}{
+		{[]Option{WithTable("public.users")}, "FROM public.users WHERE"},
+		{[]Option{WithSchema("public"), WithTable("public.users")}, "FROM public.users WHERE"},
+		{[]Option{WithSchema("tenant"), WithTable("public.users u")}, "FROM public.users u WHERE"},
+		{[]Option{WithSchema("public"), WithTable("public.users"), WithTableAlias("u")}, "FROM public.users AS u WHERE"},
+		{[]Option{WithSchema("public"), WithTable("public.users"), WithQuotedTable()}, `FROM "public"."users" WHERE`},
+	}
+	for _, c := range cases {
+		options := append([]Option{WithStruct(User{}), WithFilter("age", OpGt, 30)}, c.options...)
+		query, _ := mustGenerateSQL(t, options)
+		if !strings.Contains(query, c.from) {
+			t.Errorf("Expected %s, got: %s", c.from, query)
+		}
+	}
+
+	p, err := NewPaginator(WithTable("sales.orders o"), WithStruct(User{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, args := MySQLEstimatedCount(p)
+	if !reflect.DeepEqual(args, []interface{}{"sales", "orders"}) {
+		t.Errorf("Expected the estimate args [sales orders], got: %v", args)
+	}
+}
+
+// TestWithHaving tests grouping with multiple HAVING clauses.
+func TestWithHaving(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.age"),
+		WithColumn("COUNT(users.id) AS total"),
+		WithFilter("name", OpLike, "john"),
+		WithGroupBy("users.age"),
+		WithHaving("COUNT(users.id) > ?", 5),
+		WithHaving("SUM(users.age) < ?", 1000),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT users.age, COUNT(users.id) AS total FROM users WHERE users.name::TEXT ILIKE $1 GROUP BY users.age HAVING (COUNT(users.id) > $2 AND SUM(users.age) < $3) LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%john%", 5, 1000, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	WithHavingCombining("OR")(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "HAVING (COUNT(users.id) > $2 OR SUM(users.age) < $3)") {
+		t.Errorf("Expected HAVING clauses joined by OR, got: %s", query)
+	}
+
+	countQuery, countArgs := p.GenerateCountQuery()
+	expectedCountQuery := "SELECT COUNT(*) FROM (SELECT 1 FROM users WHERE users.name::TEXT ILIKE $1 GROUP BY users.age HAVING (COUNT(users.id) > $2 OR SUM(users.age) < $3)) AS grouped"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+	if !reflect.DeepEqual(countArgs, expectedArgs[:3]) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:3], countArgs)
+	}
+
+	// The estimate counts the groups of the inner query.
+	WithVacuum(true)(p)
+	countQuery, countArgs = p.GenerateCountQuery()
+	expectedCountQuery = "SELECT count_estimate('SELECT 1 FROM users WHERE users.name::TEXT ILIKE ' || quote_nullable($1::TEXT) || ' GROUP BY users.age HAVING (COUNT(users.id) > ' || quote_nullable($2::TEXT) || ' OR SUM(users.age) < ' || quote_nullable($3::TEXT) || ')');"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+	if !reflect.DeepEqual(countArgs, expectedArgs[:3]) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:3], countArgs)
+	}
+
+	// Oracle doesn't accept AS before the derived table alias.
+	WithVacuum(false)(p)
+	WithDialect(DialectOracle)(p)
+	countQuery, _ = p.GenerateCountQuery()
+	if !strings.HasSuffix(countQuery, ") grouped") || strings.Contains(countQuery, "AS grouped") {
+		t.Errorf("Expected the grouped alias without AS on Oracle, got: %s", countQuery)
+	}
+}
+
+// TestWithOrFilter tests ORing filters with the search group.
+func TestWithOrFilter(t *testing.T) {
+	type Post struct {
+		ID        int    `json:"id" paginate:"posts.id"`
+		Title     string `json:"title" paginate:"posts.title"`
+		Status    string `json:"status" paginate:"posts.status"`
+		Published bool   `json:"published" paginate:"posts.published"`
+	}
+
+	p, err := NewPaginator(
+		WithTable("posts"),
+		WithStruct(Post{}),
+		WithSearch("go"),
+		WithSearchFields([]string{"title"}),
+		WithOrFilter(WithFilter("status", OpEq, "featured")),
+		WithFilter("published", OpEq, true),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE ((posts.title::TEXT ILIKE $1) OR (posts.status = $2)) AND posts.published = $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%go%", "featured", true, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// Multiple OR filters are ANDed together inside the group.
+	WithOrFilter(WithWhereClause("posts.id < ?", 100))(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "WHERE ((posts.title::TEXT ILIKE $1) OR (posts.status = $2 AND posts.id < $3)) AND posts.published = $4") {
+		t.Errorf("Expected grouped OR filters, got: %s", query)
+	}
+
+	// Without a search the OR filters are ignored.
+	WithSearch("")(p)
+	query, _ = p.GenerateSQL()
+	if query != "SELECT * FROM posts WHERE posts.published = $1 LIMIT $2 OFFSET $3" {
+		t.Errorf("Expected OR filters ignored without a search, got: %s", query)
+	}
+}
+
+// TestWithCaseInsensitiveFields tests resolving fields regardless of their case.
+func TestWithCaseInsensitiveFields(t *testing.T) {
+	for _, field := range []string{"name", "Name", "NAME"} {
+		p, err := NewPaginator(
+			WithTable("users"),
+			WithStruct(User{}),
+			WithCaseInsensitiveFields(),
+			WithSort([]string{field}, []string{"false"}),
+			WithFilter(field, OpEq, "john"),
+		)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		query, _ := p.GenerateSQL()
+		expectedQuery := "SELECT * FROM users WHERE users.name = $1 ORDER BY users.name ASC LIMIT $2 OFFSET $3"
+		if query != expectedQuery {
+			t.Errorf("Expected query for %q:\n%s\nGot:\n%s", field, expectedQuery, query)
+		}
+	}
+
+	// Without the option only the exact case resolves.
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSort([]string{"NAME"}, []string{"false"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ := p.GenerateSQL()
+	if strings.Contains(query, "ORDER BY") {
+		t.Errorf("Expected no ORDER BY clause, got: %s", query)
+	}
+}
+
+// TestGenerateAll tests generating the data and count queries in one call.
+func TestGenerateAll(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithPage(2),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithFilter("age", OpBetween, 20, 30),
+		WithWhereClause("users.role IN (?)", []string{"admin", "staff"}),
+		WithSort([]string{"name"}, []string{"false"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args, countQuery, countArgs := p.GenerateAll()
+
+	expectedQuery, expectedArgs := p.GenerateSQL()
+	if query != expectedQuery || !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected data query:\n%s %v\nGot:\n%s %v", expectedQuery, expectedArgs, query, args)
+	}
+	expectedCountQuery, expectedCountArgs := p.GenerateCountQuery()
+	if countQuery != expectedCountQuery || !reflect.DeepEqual(countArgs, expectedCountArgs) {
+		t.Errorf("Expected count query:\n%s %v\nGot:\n%s %v", expectedCountQuery, expectedCountArgs, countQuery, countArgs)
+	}
+
+	where := query[strings.Index(query, "WHERE"):strings.Index(query, " ORDER BY")]
+	countWhere := countQuery[strings.Index(countQuery, "WHERE"):]
+	if where != countWhere {
+		t.Errorf("Expected identical WHERE clauses:\n%s\n%s", where, countWhere)
+	}
+	if !reflect.DeepEqual(args[:len(countArgs)], countArgs) {
+		t.Errorf("Expected count args to prefix data args: %v %v", countArgs, args)
+	}
+}
+
+// TestWithRowSecurity tests row-level security predicates.
+func TestWithRowSecurity(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithRowSecurity("users.owner_id = ? OR users.is_public", 42),
+		WithRowSecurity("users.tenant_id = ?", 7),
+		WithWhereCombining("OR"),
+		WithWhereClause("users.age > ?", 30),
+		WithWhereClause("users.age < ?", 20),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args, countQuery, countArgs := p.GenerateAll()
+	expectedWhere := "WHERE (users.owner_id = $1 OR users.is_public) AND (users.tenant_id = $2) AND (users.age > $3 OR users.age < $4)"
+	if !strings.Contains(query, expectedWhere) {
+		t.Errorf("Expected %q, got: %s", expectedWhere, query)
+	}
+	if !strings.HasSuffix(countQuery, expectedWhere) {
+		t.Errorf("Expected %q in count query, got: %s", expectedWhere, countQuery)
+	}
+	expectedArgs := []interface{}{42, 7, 30, 20, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	if !reflect.DeepEqual(countArgs, expectedArgs[:4]) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:4], countArgs)
+	}
+}
+
+// TestWithSoftDelete tests excluding soft-deleted rows from the data and count queries.
+func TestWithSoftDelete(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSoftDelete("users.deleted_at"),
+		WithWhereCombining("OR"),
+		WithWhereClause("users.age > ?", 30),
+		WithWhereClause("users.age < ?", 20),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _, countQuery, countArgs := p.GenerateAll()
+	expectedWhere := "WHERE users.deleted_at IS NULL AND (users.age > $1 OR users.age < $2)"
+	if !strings.Contains(query, expectedWhere) {
+		t.Errorf("Expected %q, got: %s", expectedWhere, query)
+	}
+	if !strings.HasSuffix(countQuery, expectedWhere) {
+		t.Errorf("Expected %q in count query, got: %s", expectedWhere, countQuery)
+	}
+	expectedCountArgs := []interface{}{30, 20}
+	if !reflect.DeepEqual(countArgs, expectedCountArgs) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedCountArgs, countArgs)
+	}
+}
+
+// TestWithRecencyTiebreak tests appending the recency sort after the requested sorts.
+func TestWithRecencyTiebreak(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       []Option
+		expectedOrder string
+	}{
+		{"without sorts", nil, " ORDER BY users.created_at DESC "},
+		{"after sorts", []Option{WithSort([]string{"name", "id"}, []string{"false", "true"})}, " ORDER BY users.name ASC, users.id DESC, users.created_at DESC "},
+		{"already sorted", []Option{WithRecencyTiebreak("users.age"), WithOrderBy("name,age:asc")}, " ORDER BY users.name ASC, users.age ASC "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{WithTable("users"), WithStruct(User{}), WithRecencyTiebreak("users.created_at")}, tt.options...)
+			query, _ := mustGenerateSQL(t, options)
+			if !strings.Contains(query, tt.expectedOrder) {
+				t.Errorf("Expected %q, got: %s", tt.expectedOrder, query)
+			}
+		})
+	}
+}
+
+// TestWithPartitionFilter tests emitting the partition predicate first.
+func TestWithPartitionFilter(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("age", OpGte, 18),
+		WithRowSecurity("users.tenant_id = ?", 7),
+		WithPartitionFilter("users.region", "eu"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args, countQuery, countArgs := p.GenerateAll()
+	expectedWhere := "WHERE users.region = $1 AND (users.tenant_id = $2) AND users.age >= $3"
+	if !strings.Contains(query, expectedWhere) {
+		t.Errorf("Expected %q, got: %s", expectedWhere, query)
+	}
+	if !strings.HasSuffix(countQuery, expectedWhere) {
+		t.Errorf("Expected %q in count query, got: %s", expectedWhere, countQuery)
+	}
+	expectedArgs := []interface{}{"eu", 7, 18, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	if !reflect.DeepEqual(countArgs, expectedArgs[:3]) {
+		t.Errorf("Expected count args: %v\nGot: %v", expectedArgs[:3], countArgs)
+	}
+}
+
+// TestWithSQLSideWildcards tests concatenating the search wildcards in SQL.
+func TestWithSQLSideWildcards(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSQLSideWildcards(),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithSearchField("email", SearchPrefix, "jo"),
+		WithSearchField("email", SearchSuffix, ".com"),
+		WithFilter("email", OpLike, "example"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE (users.name::TEXT ILIKE '%' || $1 || '%' OR users.email::TEXT ILIKE $2 || '%' OR users.email::TEXT ILIKE '%' || $3) AND users.email::TEXT ILIKE '%' || $4 || '%' LIMIT $5 OFFSET $6"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", "jo", ".com", "example", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	WithDialect(DialectMySQL)(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "LOWER(CAST(users.name AS CHAR)) LIKE LOWER(CONCAT('%', ?, '%'))") {
+		t.Errorf("Expected CONCAT wildcards on MySQL, got: %s", query)
+	}
+}
+
+// TestWithSearchConditionsCombining tests joining search conditions with AND and OR.
+func TestWithSearchConditionsCombining(t *testing.T) {
+	conditions := []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearchField("name", SearchContains, "john"),
+		WithSearchField("email", SearchSuffix, "@example.com"),
+		WithSearchField("age", SearchExact, "30"),
+	}
+
+	tests := []struct {
+		combining     string
+		expectedQuery string
+	}{
+		{"AND", "SELECT * FROM users WHERE (users.name::TEXT ILIKE $1 AND users.email::TEXT ILIKE $2 AND users.age::TEXT = $3) LIMIT $4 OFFSET $5"},
+		{"OR", "SELECT * FROM users WHERE (users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2 OR users.age::TEXT = $3) LIMIT $4 OFFSET $5"},
+	}
+
+	for _, tt := range tests {
+		p, err := NewPaginator(append(conditions, WithSearchConditionsCombining(tt.combining))...)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		query, args := p.GenerateSQL()
+		if query != tt.expectedQuery {
+			t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+		}
+		expectedArgs := []interface{}{"%john%", "%@example.com", "30", 10, 0}
+		if !reflect.DeepEqual(args, expectedArgs) {
+			t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+		}
+	}
+
+	p, err := NewPaginator(append(conditions,
+		WithSearchConditionsCombining("AND"),
+		WithSearch("doe"),
+		WithSearchFields([]string{"name", "email"}),
+	)...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE ((users.name::TEXT ILIKE $1 OR users.email::TEXT ILIKE $2) AND users.name::TEXT ILIKE $3 AND users.email::TEXT ILIKE $4 AND users.age::TEXT = $5) LIMIT $6 OFFSET $7"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	if _, err := NewPaginator(append(conditions, WithSearchConditionsCombining("XOR"))...); err == nil {
+		t.Error("Expected error for invalid combining operator")
+	}
+}
+
+// TestWithInlineLimitOffset tests embedding LIMIT and OFFSET as integer literals.
+func TestWithInlineLimitOffset(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithInlineLimitOffset(),
+		WithPage(3),
+		WithItemsPerPage(20),
+		WithWhereClause("age > ?", 18),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE age > $1 LIMIT 20 OFFSET 40"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// Only ints reach the clause, and out of range values are clamped.
+	WithItemsPerPage(-5)(p)
+	WithDialect(DialectOracle)(p)
+	WithSort([]string{"id"}, []string{"false"})(p)
+	query, _ = p.GenerateSQL()
+	expectedQuery = "SELECT * FROM users WHERE age > :1 ORDER BY users.id ASC OFFSET 0 ROWS FETCH NEXT 0 ROWS ONLY"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestWithPadSortDirections tests padding missing sort directions with ASC.
+func TestWithPadSortDirections(t *testing.T) {
+	tests := []struct {
+		name          string
+		directions    []string
+		expectedOrder string
+	}{
+		{"fewer directions", []string{"true"}, "ORDER BY users.name DESC, users.age ASC, users.email ASC"},
+		{"no directions", nil, "ORDER BY users.name ASC, users.age ASC, users.email ASC"},
+		{"extra directions", []string{"false", "true", "true", "true"}, "ORDER BY users.name ASC, users.age DESC, users.email DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithPadSortDirections(),
+				WithSort([]string{"name", "age", "email"}, tt.directions),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			query, _ := p.GenerateSQL()
+			expectedQuery := "SELECT * FROM users " + tt.expectedOrder + " LIMIT $1 OFFSET $2"
+			if query != expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+			}
+		})
+	}
+}
+
+// TestWithOrderBy tests parsing a compact order_by string into the sort.
+func TestWithOrderBy(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithOrderBy("name:asc, age:DESC,email"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(p.SortColumns, []string{"name", "age", "email"}) {
+		t.Errorf("Unexpected sort columns: %v", p.SortColumns)
+	}
+	if !reflect.DeepEqual(p.SortDirections, []string{"false", "true", "false"}) {
+		t.Errorf("Unexpected sort directions: %v", p.SortDirections)
+	}
+
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users ORDER BY users.name ASC, users.age DESC, users.email ASC LIMIT $1 OFFSET $2"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	for _, orderBy := range []string{"name:up", ":asc", "name:asc,,age", "name:asc:desc"} {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithOrderBy(orderBy)); err == nil {
+			t.Errorf("Expected error for order_by %q", orderBy)
+		}
+	}
+}
+
+// TestWithMaxSortColumns tests capping the number of sort columns.
+func TestWithMaxSortColumns(t *testing.T) {
+	columns := []string{"id", "name", "email", "age", "id", "name"}
+	directions := []string{"false", "false", "false", "false", "true", "true"}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSort(columns[:5], directions[:5])); err != nil {
+		t.Errorf("Expected no error at the default limit, got: %v", err)
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSort(columns, directions)); err == nil {
+		t.Error("Expected error over the default limit")
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithMaxSortColumns(2), WithSort(columns[:3], directions[:3])); err == nil {
+		t.Error("Expected error over a custom limit")
+	}
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithMaxSortColumns(0), WithSort(columns, directions)); err != nil {
+		t.Errorf("Expected no error with the limit disabled, got: %v", err)
+	}
+}
+
+// TestWithJoinSafePagination tests paginating the base table ids in a subquery before joining.
+func TestWithJoinSafePagination(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.*"),
+		WithColumn("posts.title"),
+		WithJoin("LEFT JOIN posts ON posts.user_id = users.id"),
+		WithJoinSafePagination(),
+		WithFilter("age", OpGte, 18),
+		WithSort([]string{"name"}, []string{"false"}),
+		WithPage(2),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args, countQuery, countArgs := p.GenerateAll()
+	expectedQuery := "SELECT users.*, posts.title FROM users LEFT JOIN posts ON posts.user_id = users.id WHERE users.id IN (SELECT users.id FROM users WHERE users.age >= $1 ORDER BY users.name ASC LIMIT $2 OFFSET $3) ORDER BY users.name ASC"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, 10, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	expectedCountQuery := "SELECT COUNT(users.id) FROM users WHERE users.age >= $1"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+	if !reflect.DeepEqual(countArgs, []interface{}{18}) {
+		t.Errorf("Expected count args: [18]\nGot: %v", countArgs)
+	}
+
+	// MySQL rejects LIMIT in an IN subquery, so the ids go through a derived table.
+	WithDialect(DialectMySQL)(p)
+	query, _ = p.GenerateSQL()
+	expectedQuery = "SELECT users.*, posts.title FROM users LEFT JOIN posts ON posts.user_id = users.id WHERE users.id IN (SELECT * FROM (SELECT users.id FROM users WHERE users.age >= ? ORDER BY users.name ASC LIMIT ? OFFSET ?) AS paginated_ids) ORDER BY users.name ASC"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	WithDialect(DialectPostgres)(p)
+
+	// Without joins the query is unchanged.
+	p.Joins = nil
+	query, _ = p.GenerateSQL()
+	expectedQuery = "SELECT users.*, posts.title FROM users WHERE users.age >= $1 ORDER BY users.name ASC LIMIT $2 OFFSET $3"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestMustField tests valid and invalid field references.
+func TestMustField(t *testing.T) {
+	if field := MustField(User{}, "email"); field != "email" {
+		t.Errorf("Expected field email, got: %s", field)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected panic for an unknown field")
+		}
+		if !strings.Contains(fmt.Sprint(r), `unknown field "emial"`) {
+			t.Errorf("Unexpected panic message: %v", r)
+		}
+	}()
+	MustField(User{}, "emial")
+}
+
+// TestWithDataAndCountOnlyWhere tests clauses applied only to the data or the count query.
+func TestWithDataAndCountOnlyWhere(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithWhereClause("age > ?", 18),
+		WithDataOnlyWhere("name <> ?", "admin"),
+		WithCountOnlyWhere("id <> ?", 1),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery := "SELECT * FROM users WHERE age > $1 AND name <> $2 LIMIT $3 OFFSET $4"
+	expectedArgs := []interface{}{18, "admin", 10, 0}
+	expectedCountQuery := "SELECT COUNT(users.id) FROM users WHERE age > $1 AND id <> $2"
+	expectedCountArgs := []interface{}{18, 1}
+
+	query, args := p.GenerateSQL()
+	countQuery, countArgs := p.GenerateCountQuery()
+	allQuery, allArgs, allCountQuery, allCountArgs := p.GenerateAll()
+
+	for _, got := range []struct {
+		query, countQuery string
+		args, countArgs   []interface{}
+	}{
+		{query, countQuery, args, countArgs},
+		{allQuery, allCountQuery, allArgs, allCountArgs},
+	} {
+		if got.query != expectedQuery {
+			t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, got.query)
+		}
+		if !reflect.DeepEqual(got.args, expectedArgs) {
+			t.Errorf("Expected args: %v\nGot: %v", expectedArgs, got.args)
+		}
+		if got.countQuery != expectedCountQuery {
+			t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, got.countQuery)
+		}
+		if !reflect.DeepEqual(got.countArgs, expectedCountArgs) {
+			t.Errorf("Expected count args: %v\nGot: %v", expectedCountArgs, got.countArgs)
+		}
+	}
+}
+
+// TestWithSelectWindow tests adding a window function column.
+func TestWithSelectWindow(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.name"),
+		WithSelectWindow("ROW_NUMBER() OVER (ORDER BY users.age DESC)", "rank"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT users.name, ROW_NUMBER() OVER (ORDER BY users.age DESC) AS rank FROM users LIMIT $1 OFFSET $2"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	invalid := []struct{ expression, alias string }{
+		{"ROW_NUMBER() OVER (ORDER BY age); DROP TABLE users; --", "rank"},
+		{"(SELECT password FROM admins LIMIT 1)", "rank"},
+		{"ROW_NUMBER() OVER (ORDER BY age)", "rank FROM admins --"},
+		{"RANK() OVER (PARTITION BY (SELECT 1))", "rank"},
+	}
+	for _, tt := range invalid {
+		if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSelectWindow(tt.expression, tt.alias)); err == nil {
+			t.Errorf("Expected error for window column %q AS %q", tt.expression, tt.alias)
+		}
+	}
+}
+
+// TestSetDeterministic tests that the SQL doesn't depend on the order of the options.
+func TestSetDeterministic(t *testing.T) {
+	SetDeterministic(true)
+	defer SetDeterministic(false)
+
+	options := []Option{
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithSearchField("email", SearchPrefix, "jo"),
+		WithSearchField("name", SearchExact, "john"),
+		WithFilter("age", OpGte, 18),
+		WithFilter("name", OpNeq, "admin"),
+		WithFilter("age", OpLt, 65),
+		WithColumnComparison("name", "<>", "email"),
+		WithColumnComparison("age", ">", "id"),
+	}
+
+	expected, expectedArgs := mustGenerateSQL(t, options)
+	for run := 0; run < 20; run++ {
+		shuffled := append([]Option{}, options...)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		query, args := mustGenerateSQL(t, shuffled)
+		if query != expected || !reflect.DeepEqual(args, expectedArgs) {
+			t.Fatalf("Expected identical SQL:\n%s %v\nGot:\n%s %v", expected, expectedArgs, query, args)
+		}
+	}
+
+	expectedQuery := "SELECT * FROM users WHERE (users.email::TEXT ILIKE $1 OR users.name::TEXT ILIKE $2 OR users.email::TEXT ILIKE $3 OR users.name::TEXT = $4) AND users.age >= $5 AND users.age < $6 AND users.name <> $7 AND users.age > users.id AND users.name <> users.email LIMIT $8 OFFSET $9"
+	if expected != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, expected)
+	}
+}
+
+// mustGenerateSQL creates a paginator from the options and generates its SQL.
+func mustGenerateSQL(t *testing.T, options []Option) (string, []interface{}) {
+	t.Helper()
+	p, err := NewPaginator(options...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return p.GenerateSQL()
+}
+
+// TestVacuumCountQueryArgs tests that the vacuum count query keeps one bound placeholder per argument.
+func TestVacuumCountQueryArgs(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithVacuum(true),
+		WithSQLSideWildcards(),
+		WithSearch("o'brien"),
+		WithSearchFields([]string{"name"}),
+		WithFilter("id", OpIn, 1, 2),
+		WithWhereClause("status = 'active'"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateCountQuery()
+	expectedQuery := "SELECT count_estimate('SELECT 1 FROM users WHERE (users.name::TEXT ILIKE ''%'' || ' || quote_nullable($1::TEXT) || ' || ''%'') AND users.id IN (' || quote_nullable($2::TEXT) || ', ' || quote_nullable($3::TEXT) || ') AND status = ''active''');"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"o'brien", 1, 2}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	if placeholders := strings.Count(query, "quote_nullable($"); placeholders != len(args) {
+		t.Errorf("Expected %d placeholders, got %d", len(args), placeholders)
+	}
+}
+
+// TestWithPageAndItemsPerPageString tests setting the page and items per page from strings.
+func TestWithPageAndItemsPerPageString(t *testing.T) {
+	tests := []struct {
+		name                 string
+		page, itemsPerPage   string
+		expectedPage         int
+		expectedItemsPerPage int
+		expectError          bool
+	}{
+		{"valid", "3", " 25 ", 3, 25, false},
+		{"empty keeps defaults", "", "", 1, 10, false},
+		{"non-numeric page", "abc", "10", 0, 0, true},
+		{"non-numeric items per page", "1", "10; DROP TABLE users", 0, 0, true},
+		{"zero page", "0", "", 0, 0, true},
+		{"negative items per page", "", "-5", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithPageString(tt.page),
+				WithItemsPerPageString(tt.itemsPerPage),
+			)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if p.Page != tt.expectedPage || p.ItemsPerPage != tt.expectedItemsPerPage {
+				t.Errorf("Expected page %d with %d items, got page %d with %d items", tt.expectedPage, tt.expectedItemsPerPage, p.Page, p.ItemsPerPage)
+			}
+		})
+	}
+}
+
+// TestWithInclude tests exposing eager-load relation hints without changing the SQL.
+func TestWithInclude(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithInclude("orders, profile"),
+		WithInclude("roles"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedIncludes := []string{"orders", "profile", "roles"}
+	if !reflect.DeepEqual(p.Includes, expectedIncludes) {
+		t.Errorf("Expected includes: %v\nGot: %v", expectedIncludes, p.Includes)
+	}
+
+	query, _ := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users LIMIT $1 OFFSET $2"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+}
+
+// TestWithTableAlias tests the FROM clause and count id column with a table alias.
+func TestWithTableAlias(t *testing.T) {
+	tests := []struct {
+		name               string
+		options            []Option
+		expectedQuery      string
+		expectedCountQuery string
+	}{
+		{
+			"table and alias",
+			[]Option{WithTableAlias("u")},
+			"SELECT * FROM users AS u LIMIT $1 OFFSET $2",
+			"SELECT COUNT(u.id) FROM users AS u",
+		},
+		{
+			"schema, table and alias",
+			[]Option{WithSchema("public"), WithTableAlias("u")},
+			"SELECT * FROM public.users AS u LIMIT $1 OFFSET $2",
+			"SELECT COUNT(u.id) FROM public.users AS u",
+		},
+		{
+			"schema-qualified table and alias",
+			[]Option{WithTable("public.users"), WithTableAlias("u")},
+			"SELECT * FROM public.users AS u LIMIT $1 OFFSET $2",
+			"SELECT COUNT(u.id) FROM public.users AS u",
+		},
+		{
+			"quoted schema and table",
+			[]Option{WithSchema("public"), WithTableAlias("u"), WithQuotedTable()},
+			`SELECT * FROM "public"."users" AS u LIMIT $1 OFFSET $2`,
+			`SELECT COUNT(u.id) FROM "public"."users" AS u`,
+		},
+		{
+			"oracle",
+			[]Option{WithSchema("hr"), WithTableAlias("u"), WithDialect(DialectOracle), WithSort([]string{"name"}, []string{"false"})},
+			"SELECT * FROM hr.users u ORDER BY users.name ASC OFFSET :1 ROWS FETCH NEXT :2 ROWS ONLY",
+			"SELECT COUNT(u.id) FROM hr.users u",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(append([]Option{WithTable("users"), WithStruct(User{})}, tt.options...)...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			query, _ := p.GenerateSQL()
+			if query != tt.expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", tt.expectedQuery, query)
+			}
+			countQuery, _ := p.GenerateCountQuery()
+			if countQuery != tt.expectedCountQuery {
+				t.Errorf("Expected count query:\n%s\nGot:\n%s", tt.expectedCountQuery, countQuery)
+			}
+		})
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithTableAlias("u; DROP TABLE users")); err == nil {
+		t.Error("Expected error for an invalid alias")
+	}
+}
+
+// TestWithSearchRank tests selecting and ordering by the search relevance.
+func TestWithSearchRank(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithSearchRank("rank"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT *, ts_rank(to_tsvector(concat_ws(' ', users.name::TEXT, users.email::TEXT)), plainto_tsquery($1)) AS rank FROM users WHERE (users.name::TEXT ILIKE $2 OR users.email::TEXT ILIKE $3) ORDER BY rank DESC LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", "%john%", "%john%", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	// An explicit sort overrides the rank ordering.
+	WithSort([]string{"name"}, []string{"false"})(p)
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "AS rank FROM") || !strings.Contains(query, "ORDER BY users.name ASC LIMIT") {
+		t.Errorf("Expected the rank column with the explicit sort, got: %s", query)
+	}
+
+	// Without a search there is nothing to rank.
+	WithSearch("")(p)
+	query, _ = p.GenerateSQL()
+	if strings.Contains(query, "ts_rank") {
+		t.Errorf("Expected no rank column without a search, got: %s", query)
+	}
+
+	// The join-safe id subquery doesn't select the rank, so only the outer query orders by it.
+	p, err = NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name"}),
+		WithSearchRank("rank"),
+		WithJoin("INNER JOIN orders ON users.id = orders.user_id"),
+		WithJoinSafePagination(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ = p.GenerateSQL()
+	expectedQuery = "SELECT *, ts_rank(to_tsvector(concat_ws(' ', users.name::TEXT)), plainto_tsquery($1)) AS rank FROM users INNER JOIN orders ON users.id = orders.user_id WHERE users.id IN (SELECT users.id FROM users WHERE (users.name::TEXT ILIKE $2) LIMIT $3 OFFSET $4) ORDER BY rank DESC"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithSearchRank("rank"), WithDialect(DialectMySQL)); err == nil {
+		t.Error("Expected error for a search rank on MySQL")
+	}
+}
+
+// TestWithMaxSQLLength tests rejecting generated queries over the maximum length.
+func TestWithMaxSQLLength(t *testing.T) {
+	ids := make([]interface{}, 500)
+	for i := range ids {
+		ids[i] = i
+	}
+
+	_, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithMaxSQLLength(1000),
+		WithFilter("id", OpIn, ids...),
+	)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the maximum of 1000") {
+		t.Errorf("Expected a query length error, got: %v", err)
+	}
+
+	metricsCalls := 0
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithMaxSQLLength(1000),
+		WithFilter("id", OpIn, ids[:10]...),
+		WithMetricsHook(func(QueryMetrics) { metricsCalls++ }),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metricsCalls != 0 {
+		t.Errorf("Expected no metrics while checking the length, got %d calls", metricsCalls)
+	}
+	p.GenerateSQL()
+	if metricsCalls != 1 {
+		t.Errorf("Expected metrics once the query is generated, got %d calls", metricsCalls)
+	}
+}
+
+// TestWithCountColumn tests count queries with an explicit count column and no struct.
+func TestWithCountColumn(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("orders"),
+		WithCountColumn("orders.order_id"),
+		WithWhereClause("status = ?", "paid"),
+		WithSearch("ignored"),
+		WithSearchFields([]string{"name"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	countQuery, countArgs := p.GenerateCountQuery()
+	expectedCountQuery := "SELECT COUNT(orders.order_id) FROM orders WHERE status = $1"
+	if countQuery != expectedCountQuery {
+		t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+	}
+	if !reflect.DeepEqual(countArgs, []interface{}{"paid"}) {
+		t.Errorf("Expected count args: [paid]\nGot: %v", countArgs)
+	}
+
+	if _, err := NewPaginator(WithTable("orders")); err == nil {
+		t.Error("Expected error without a struct or count column")
+	}
+}
+
+// TestWithGroupByRollupAndCube tests the ROLLUP and CUBE grouping syntax.
+func TestWithGroupByRollupAndCube(t *testing.T) {
+	tests := []struct {
+		name          string
+		option        Option
+		expectedGroup string
+	}{
+		{"rollup", WithGroupByRollup("users.country", "users.city"), "GROUP BY users.age, ROLLUP(users.country, users.city)"},
+		{"cube", WithGroupByCube("users.country", "users.city"), "GROUP BY users.age, CUBE(users.country, users.city)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("users"),
+				WithStruct(User{}),
+				WithColumn("users.country"),
+				WithColumn("users.city"),
+				WithColumn("COUNT(*)"),
+				WithGroupBy("users.age"),
+				tt.option,
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			query, _ := p.GenerateSQL()
+			expectedQuery := "SELECT users.country, users.city, COUNT(*) FROM users " + tt.expectedGroup + " LIMIT $1 OFFSET $2"
+			if query != expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+			}
+
+			countQuery, _ := p.GenerateCountQuery()
+			expectedCountQuery := "SELECT COUNT(*) FROM (SELECT 1 FROM users " + tt.expectedGroup + ") AS grouped"
+			if countQuery != expectedCountQuery {
+				t.Errorf("Expected count query:\n%s\nGot:\n%s", expectedCountQuery, countQuery)
+			}
+		})
+	}
+}
+
+// TestWithSearchWeighted tests the weighted tsvector construction and rank ordering.
+func TestWithSearchWeighted(t *testing.T) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearchWeighted("john", map[string]float64{"name": 1, "email": 0.5}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	vector := "setweight(to_tsvector(coalesce(users.email::TEXT, '')), 'B') || setweight(to_tsvector(coalesce(users.name::TEXT, '')), 'A')"
+	expectedQuery := "SELECT *, ts_rank(" + vector + ", plainto_tsquery($1)) AS rank FROM users WHERE " + vector + " @@ plainto_tsquery($2) ORDER BY rank DESC LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", "john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	for weight, label := range map[float64]string{2: "A", 1: "A", 0.4: "B", 0.3: "C", 0.1: "D", 0: "D"} {
+		if got := weightLabel(weight); got != label {
+			t.Errorf("Expected weight %v to map to %s, got %s", weight, label, got)
+		}
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithDialect(DialectMySQL), WithSearchWeighted("john", map[string]float64{"name": 1})); err == nil {
+		t.Error("Expected error for a weighted search on MySQL")
+	}
+}
+
+// BenchmarkGenerateCountQuery compares the count generation with the id column
+// memoized by NewPaginator against resolving it by reflection on every call,
+// dropping the cached tag mapping so the resolution isn't served by it.
+func BenchmarkGenerateCountQuery(b *testing.B) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFilter("age", OpGte, 18),
+	)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.Run("memoized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p.GenerateCountQuery()
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		cacheKey := fieldNameKey{structType: reflect.TypeOf(User{}), key: "json", keyTarget: "paginate"}
+		for i := 0; i < b.N; i++ {
+			p.idColumn = ""
+			fieldNames.Delete(cacheKey)
+			p.GenerateCountQuery()
+		}
+	})
+}
+
+// BenchmarkGetFieldName compares the cached field resolution against the
+// reflection over the struct fields.
+func BenchmarkGetFieldName(b *testing.B) {
+	rt := reflect.TypeOf(User{})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			getFieldName("email", "json", "paginate", User{})
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			findFieldName("email", "json", "paginate", rt)
+		}
+	})
+}
+
+// BenchmarkGenerateSQL measures the SQL generation of a filtered, searched and
+// sorted query.
+func BenchmarkGenerateSQL(b *testing.B) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithFilter("age", OpGte, 18),
+		WithFilter("id", OpIn, 1, 2, 3),
+		WithSort([]string{"name"}, []string{"true"}),
+	)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.GenerateSQL()
+	}
+}
+
+// BenchmarkGenerateSQLPooled measures the SQL generation of BenchmarkGenerateSQL
+// reusing the pooled buffers.
+func BenchmarkGenerateSQLPooled(b *testing.B) {
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithSearch("john"),
+		WithSearchFields([]string{"name", "email"}),
+		WithFilter("age", OpGte, 18),
+		WithFilter("id", OpIn, 1, 2, 3),
+		WithSort([]string{"name"}, []string{"true"}),
+	)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _, release := p.GenerateSQLPooled()
+		release()
+	}
+}
+
+// BenchmarkGenerateSQLUnfiltered measures the SQL generation of an unfiltered list.
+func BenchmarkGenerateSQLUnfiltered(b *testing.B) {
+	p, err := NewPaginator(WithTable("users"), WithStruct(User{}))
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p.GenerateSQL()
 	}
 }
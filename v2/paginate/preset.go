@@ -0,0 +1,38 @@
+package paginate
+
+import (
+	"fmt"
+	"sync"
+)
+
+// presets holds the options registered by RegisterPreset.
+var presets = struct {
+	sync.RWMutex
+	options map[string][]Option
+}{options: map[string][]Option{}}
+
+// RegisterPreset registers a named bundle of options, e.g. the filters of
+// "active users", to be applied by WithPreset. Registering a name again
+// replaces its options.
+func RegisterPreset(name string, options ...Option) {
+	presets.Lock()
+	defer presets.Unlock()
+	presets.options[name] = options
+}
+
+// WithPreset applies the options registered under the name. An unknown name
+// makes NewPaginator return an error.
+func WithPreset(name string) Option {
+	return func(params *QueryParams) {
+		presets.RLock()
+		options, ok := presets.options[name]
+		presets.RUnlock()
+		if !ok {
+			params.errs = append(params.errs, fmt.Errorf("unknown preset %q", name))
+			return
+		}
+		for _, option := range options {
+			option(params)
+		}
+	}
+}
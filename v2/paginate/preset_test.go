@@ -0,0 +1,35 @@
+package paginate
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWithPreset tests applying the filters of a registered preset.
+func TestWithPreset(t *testing.T) {
+	RegisterPreset("adults", WithFilter("age", OpGte, 18), WithFilter("email", OpIsNotNull))
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithPreset("adults"),
+		WithFilter("name", OpEq, "john"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.age >= $1 AND users.email IS NOT NULL AND users.name = $2 LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{18, "john", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	if _, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithPreset("unknown")); err == nil {
+		t.Error("Expected error for an unknown preset")
+	}
+}
@@ -0,0 +1,347 @@
+package paginate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithQuery applies the pagination params of the query values, so handlers can
+// go straight from r.URL.Query() to the paginator:
+//
+//   - page and limit set the page and the items per page;
+//   - search and search_fields (comma-separated) set the search;
+//   - sort sets the sort from comma-separated fields, "-" prefixed for DESC,
+//     e.g. sort=-created_at,name; the legacy sort_columns and sort_directions
+//     ("true" for DESC) are used as set by WithSortPrecedence, and order_by
+//     without either;
+//   - fields and include set the sparse fieldset and relation hints;
+//   - op[field]=value adds a filter, with comma-separated values for in, notin,
+//     ini, between and notbetween, e.g. gte[age]=18 or in[status]=active,pending;
+//   - isnull=field and isnotnull=field add null checks;
+//   - field=value, repeatable, adds an in filter for the fields set by
+//     WithImplicitInFields, e.g. status=active&status=pending.
+//
+// Unknown params, including bracketed ones whose op isn't a supported operator
+// such as page[size], are ignored; invalid values make NewPaginator return an
+// error.
+func WithQuery(values url.Values) Option {
+	return func(params *QueryParams) {
+		WithPageString(values.Get("page"))(params)
+		WithItemsPerPageString(values.Get("limit"))(params)
+		if values.Has("search") {
+			params.Search = values.Get("search")
+		}
+		if values.Has("search_fields") {
+			params.SearchFields = splitList(values.Get("search_fields"))
+		}
+
+		applyQuerySort(params, values)
+
+		if values.Has("fields") {
+			WithFields(values.Get("fields"))(params)
+		}
+		WithInclude(values["include"]...)(params)
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == string(OpIsNull) || key == string(OpIsNotNull) {
+				for _, field := range splitList(strings.Join(values[key], ",")) {
+					params.Filters = append(params.Filters, Filter{Field: field, Operator: Operator(key)})
+				}
+				continue
+			}
+			if slices.Contains(params.ImplicitInFields, key) {
+				var in []interface{}
+				for _, value := range values[key] {
+					in = append(in, value)
+				}
+				params.Filters = append(params.Filters, Filter{Field: key, Operator: OpIn, Values: in})
+				continue
+			}
+			operator, field, ok := parseFilterKey(key)
+			if !ok || !operator.known() {
+				continue
+			}
+			for _, value := range values[key] {
+				params.Filters = append(params.Filters, Filter{Field: field, Operator: operator, Values: filterValues(operator, value)})
+			}
+		}
+	}
+}
+
+// WithQueryJSON applies the pagination params of a JSON request body like
+// WithQuery, with the params as keys and lists as arrays. Filters are read from
+// op[field] keys or nested under the filters root key set by
+// WithJSONFiltersKey, e.g. {"page": 2, "filters": {"gte": {"age": 18}}}.
+// Malformed bodies make NewPaginator return an error.
+func WithQueryJSON(body []byte) Option {
+	return func(params *QueryParams) {
+		values, err := jsonQueryValues(body, params.JSONFiltersKey)
+		if err != nil {
+			params.errs = append(params.errs, fmt.Errorf("invalid JSON query: %w", err))
+			return
+		}
+		WithQuery(values)(params)
+	}
+}
+
+// WithJSONFiltersKey sets the root key of the nested filters read by the
+// following WithQueryJSON options, "filters" by default.
+func WithJSONFiltersKey(key string) Option {
+	return func(params *QueryParams) {
+		params.JSONFiltersKey = key
+	}
+}
+
+// jsonQueryValues flattens a JSON request body into query values, merging the
+// operators nested under the filters key into op[field] params.
+func jsonQueryValues(body []byte, filtersKey string) (url.Values, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, err
+	}
+	if filtersKey == "" {
+		filtersKey = "filters"
+	}
+
+	values := url.Values{}
+	for key, value := range object {
+		if key != filtersKey {
+			if value, ok := jsonQueryValue(value); ok {
+				values.Add(key, value)
+			}
+			continue
+		}
+		operators, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%q must be an object of operators", key)
+		}
+		for operator, fields := range operators {
+			fieldValues, ok := fields.(map[string]interface{})
+			if !ok {
+				// Null checks list their fields, e.g. {"isnull": ["email"]}.
+				if value, ok := jsonQueryValue(fields); ok {
+					values.Add(operator, value)
+				}
+				continue
+			}
+			for field, value := range fieldValues {
+				if value, ok := jsonQueryValue(value); ok {
+					values.Add(operator+"["+field+"]", value)
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// jsonQueryValue returns the query param value of a JSON value, joining arrays
+// with commas. Nulls and objects have none.
+func jsonQueryValue(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case json.Number:
+		return value.String(), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			if item, ok := jsonQueryValue(item); ok {
+				items = append(items, item)
+			}
+		}
+		return strings.Join(items, ","), true
+	}
+	return "", false
+}
+
+// MergeQuery layers request query values over base values, e.g. service
+// defaults: the non-empty page, limit, search, sort and fields params of
+// override win, while filter, null check and include params are unioned so the
+// request extends the base filters.
+func MergeQuery(base, override url.Values) url.Values {
+	merged := url.Values{}
+	for key, values := range base {
+		merged[key] = append([]string(nil), values...)
+	}
+	for key, values := range override {
+		if isListParam(key) {
+			merged[key] = append(merged[key], values...)
+		} else if len(values) > 0 && values[0] != "" {
+			merged[key] = append([]string(nil), values...)
+		}
+	}
+	return merged
+}
+
+// isListParam reports whether the query param accumulates values instead of
+// holding a single one.
+func isListParam(key string) bool {
+	if operator, _, ok := parseFilterKey(key); ok && operator.known() {
+		return true
+	}
+	switch key {
+	case "include", string(OpIsNull), string(OpIsNotNull):
+		return true
+	}
+	return false
+}
+
+// SortPrecedence decides which sort params WithQuery uses when both the sort
+// param and the legacy sort_columns and sort_directions params are present.
+type SortPrecedence int
+
+// Supported sort precedences.
+const (
+	// SortPrecedenceNew uses the sort param and ignores the legacy params.
+	SortPrecedenceNew SortPrecedence = iota
+	// SortPrecedenceLegacy uses the legacy params and ignores the sort param.
+	SortPrecedenceLegacy
+	// SortPrecedenceMerge sorts by the sort param, then by the legacy params.
+	SortPrecedenceMerge
+)
+
+// WithSortPrecedence sets the SortPrecedence used by the following WithQuery
+// options, SortPrecedenceNew by default.
+func WithSortPrecedence(precedence SortPrecedence) Option {
+	return func(params *QueryParams) {
+		params.SortPrecedence = precedence
+	}
+}
+
+// WithImplicitInFields makes the following WithQuery options bind the plain
+// params of the fields, repeated or not, into an in filter, for clients that
+// send status=active&status=pending instead of in[status]=active,pending.
+func WithImplicitInFields(fields ...string) Option {
+	return func(params *QueryParams) {
+		params.ImplicitInFields = append(params.ImplicitInFields, fields...)
+	}
+}
+
+// applyQuerySort sets the sort from the sort, legacy sort or order_by params.
+func applyQuerySort(params *QueryParams, values url.Values) {
+	hasNew, hasLegacy := values.Has("sort"), values.Has("sort_columns")
+	if !hasNew && !hasLegacy {
+		if values.Has("order_by") {
+			WithOrderBy(values.Get("order_by"))(params)
+		}
+		return
+	}
+
+	var columns, directions []string
+	if hasNew && (!hasLegacy || params.SortPrecedence != SortPrecedenceLegacy) {
+		columns, directions = ParseSort(splitList(values.Get("sort")))
+	}
+	if hasLegacy && (!hasNew || params.SortPrecedence != SortPrecedenceNew) {
+		columns = append(columns, splitList(values.Get("sort_columns"))...)
+		directions = append(directions, splitList(values.Get("sort_directions"))...)
+	}
+	params.SortColumns, params.SortDirections = columns, directions
+}
+
+// ParseSort parses sort tokens in the "-field" convention into the columns and
+// directions taken by WithSort: "-field" sorts DESC ("true"), while "+field"
+// and bare "field" sort ASC ("false"). Empty tokens are skipped.
+func ParseSort(tokens []string) (columns []string, directions []string) {
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		column := strings.TrimLeft(token, "+-")
+		if column == "" {
+			continue
+		}
+		columns = append(columns, column)
+		directions = append(directions, strconv.FormatBool(strings.HasPrefix(token, "-")))
+	}
+	return columns, directions
+}
+
+// ParseFilterParams extracts the op[field]=value params of the query values
+// whose op is a supported operator,
+// grouped by operator and field, so filters can be inspected without a
+// paginator, e.g. for cache keys or audit logs. Values are kept as sent.
+func ParseFilterParams(values url.Values) map[string]map[string][]string {
+	filters := map[string]map[string][]string{}
+	for key, keyValues := range values {
+		operator, field, ok := parseFilterKey(key)
+		if !ok || !operator.known() {
+			continue
+		}
+		if filters[string(operator)] == nil {
+			filters[string(operator)] = map[string][]string{}
+		}
+		filters[string(operator)][field] = append(filters[string(operator)][field], keyValues...)
+	}
+	return filters
+}
+
+// parseFilterKey splits a filter key in the `op[field]` convention.
+func parseFilterKey(key string) (Operator, string, bool) {
+	open := strings.Index(key, "[")
+	if open <= 0 || !strings.HasSuffix(key, "]") || open+1 == len(key)-1 {
+		return "", "", false
+	}
+	return Operator(key[:open]), key[open+1 : len(key)-1], true
+}
+
+// filterValues returns the filter values of a query param value, splitting the
+// comma-separated lists of the multi-value operators.
+func filterValues(operator Operator, value string) []interface{} {
+	switch operator {
+	case OpIn, OpNotIn, OpInI, OpBetween, OpNotBetween:
+		var values []interface{}
+		for _, item := range strings.Split(value, ",") {
+			values = append(values, strings.TrimSpace(item))
+		}
+		return values
+	}
+	return []interface{}{value}
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// WithFlagParams adds an equality filter for each of the fields present in the
+// query values as a boolean flag, so `?featured` means `featured = true`. A
+// flag without a value or set to a true value matches true, one set to a false
+// value matches false; anything else makes NewPaginator return an error.
+func WithFlagParams(values url.Values, fields ...string) Option {
+	return func(params *QueryParams) {
+		for _, field := range fields {
+			if !values.Has(field) {
+				continue
+			}
+			flag := true
+			if value := values.Get(field); value != "" {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					params.errs = append(params.errs, fmt.Errorf("invalid flag %q: %q is not a boolean", field, value))
+					continue
+				}
+				flag = parsed
+			}
+			params.Filters = append(params.Filters, Filter{Field: field, Operator: OpEq, Values: []interface{}{flag}})
+		}
+	}
+}
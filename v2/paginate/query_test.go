@@ -0,0 +1,262 @@
+package paginate
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Post struct used for testing flag params.
+type Post struct {
+	ID        int    `json:"id" paginate:"posts.id"`
+	Title     string `json:"title" paginate:"posts.title"`
+	Featured  bool   `json:"featured" paginate:"posts.featured"`
+	Published bool   `json:"published" paginate:"posts.published"`
+}
+
+// TestWithFlagParams tests binding registered flag params into equality filters.
+func TestWithFlagParams(t *testing.T) {
+	values, err := url.ParseQuery("featured&published=false&archived&title=go")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p, err := NewPaginator(
+		WithTable("posts"),
+		WithStruct(Post{}),
+		WithFlagParams(values, "featured", "published", "pinned"),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE posts.featured = $1 AND posts.published = $2 LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{true, false, 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	values.Set("featured", "yes please")
+	if _, err := NewPaginator(WithTable("posts"), WithStruct(Post{}), WithFlagParams(values, "featured")); err == nil {
+		t.Error("Expected error for a non-boolean flag value")
+	}
+}
+
+// TestWithQuery tests applying pagination params and filters from url.Values.
+func TestWithQuery(t *testing.T) {
+	values, err := url.ParseQuery("page=2&limit=5&search=go&search_fields=title&sort=-published,title&gte[id]=10&in[title]=a,b&isnull=featured&include=author&utm_source=mail")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p, err := NewPaginator(
+		WithTable("posts"),
+		WithStruct(Post{}),
+		WithQuery(values),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE (posts.title::TEXT ILIKE $1) AND posts.id >= $2 AND posts.title IN ($3, $4) AND posts.featured IS NULL ORDER BY posts.published DESC, posts.title ASC LIMIT $5 OFFSET $6"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"%go%", "10", "a", "b", 5, 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+	if !reflect.DeepEqual(p.Includes, []string{"author"}) {
+		t.Errorf("Expected includes [author], got: %v", p.Includes)
+	}
+
+	for _, invalid := range []string{"page=abc", "limit=-1", "between[id]=1", "order_by=title:up"} {
+		values, _ := url.ParseQuery(invalid)
+		if _, err := NewPaginator(WithTable("posts"), WithStruct(Post{}), WithQuery(values)); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+
+	ignored, _ := url.ParseQuery("page[size]=10&filter[title]=go&regex[title]=.*")
+	p, err = NewPaginator(WithTable("posts"), WithStruct(Post{}), WithQuery(ignored))
+	if err != nil {
+		t.Fatalf("Unexpected error for unknown bracketed params: %v", err)
+	}
+	if len(p.Filters) != 0 {
+		t.Errorf("Expected unknown bracketed params to be ignored, got filters: %v", p.Filters)
+	}
+}
+
+// TestWithSortPrecedence tests each sort precedence with both sort params present.
+func TestWithSortPrecedence(t *testing.T) {
+	values, err := url.ParseQuery("sort=-title&sort_columns=published,id&sort_directions=true,false")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		precedence    SortPrecedence
+		expectedOrder string
+	}{
+		{"new", SortPrecedenceNew, "ORDER BY posts.title DESC"},
+		{"legacy", SortPrecedenceLegacy, "ORDER BY posts.published DESC, posts.id ASC"},
+		{"merge", SortPrecedenceMerge, "ORDER BY posts.title DESC, posts.published DESC, posts.id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPaginator(
+				WithTable("posts"),
+				WithStruct(Post{}),
+				WithSortPrecedence(tt.precedence),
+				WithQuery(values),
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			query, _ := p.GenerateSQL()
+			expectedQuery := "SELECT * FROM posts " + tt.expectedOrder + " LIMIT $1 OFFSET $2"
+			if query != expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+			}
+		})
+	}
+}
+
+// TestMergeQuery tests layering request query values over base values.
+func TestMergeQuery(t *testing.T) {
+	base, _ := url.ParseQuery("limit=20&sort=-published&eq[published]=true&include=author")
+	override, _ := url.ParseQuery("limit=5&sort=&gte[id]=10&eq[published]=false&include=tags&page=2")
+
+	merged := MergeQuery(base, override)
+	expected := url.Values{
+		"limit":         {"5"},
+		"sort":          {"-published"},
+		"page":          {"2"},
+		"eq[published]": {"true", "false"},
+		"gte[id]":       {"10"},
+		"include":       {"author", "tags"},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("Expected merged values: %v\nGot: %v", expected, merged)
+	}
+	if base.Get("limit") != "20" || len(base["include"]) != 1 {
+		t.Errorf("Expected the base values to be left unchanged, got: %v", base)
+	}
+}
+
+// TestWithImplicitInFields tests binding repeated plain params into an in filter.
+func TestWithImplicitInFields(t *testing.T) {
+	values, err := url.ParseQuery("title=Go&title=Rust&featured=true&page=2")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p, err := NewPaginator(
+		WithTable("posts"),
+		WithStruct(Post{}),
+		WithImplicitInFields("title"),
+		WithQuery(values),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE posts.title IN ($1, $2) LIMIT $3 OFFSET $4"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"Go", "Rust", 10, 10}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestParseSort tests parsing the sort prefix forms.
+func TestParseSort(t *testing.T) {
+	columns, directions := ParseSort([]string{"-created_at", "+name", "id", " -title ", "", "-"})
+	expectedColumns := []string{"created_at", "name", "id", "title"}
+	expectedDirections := []string{"true", "false", "false", "true"}
+	if !reflect.DeepEqual(columns, expectedColumns) {
+		t.Errorf("Expected columns: %v\nGot: %v", expectedColumns, columns)
+	}
+	if !reflect.DeepEqual(directions, expectedDirections) {
+		t.Errorf("Expected directions: %v\nGot: %v", expectedDirections, directions)
+	}
+}
+
+// TestParseFilterParams tests extracting the filter params grouped by operator and field.
+func TestParseFilterParams(t *testing.T) {
+	values, err := url.ParseQuery("gte[age]=18&lte[age]=65&in[status]=active,pending&eq[name]=John&eq[name]=Jane&page=2&sort=-id&isnull=email&bad[=x&page[size]=10")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	filters := ParseFilterParams(values)
+	expected := map[string]map[string][]string{
+		"gte": {"age": {"18"}},
+		"lte": {"age": {"65"}},
+		"in":  {"status": {"active,pending"}},
+		"eq":  {"name": {"John", "Jane"}},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Errorf("Expected filters: %v\nGot: %v", expected, filters)
+	}
+}
+
+// TestWithQueryJSON tests binding a JSON body with a nested filters object.
+func TestWithQueryJSON(t *testing.T) {
+	body := []byte(`{
+		"page": 2,
+		"limit": 5,
+		"sort": ["-title", "id"],
+		"eq[featured]": true,
+		"filters": {
+			"in": {"id": [1, 2, 3]},
+			"like": {"title": "go"},
+			"isnull": ["published"]
+		}
+	}`)
+
+	p, err := NewPaginator(WithTable("posts"), WithStruct(Post{}), WithQueryJSON(body))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM posts WHERE posts.featured = $1 AND posts.id IN ($2, $3, $4) AND posts.published IS NULL AND posts.title::TEXT ILIKE $5 ORDER BY posts.title DESC, posts.id ASC LIMIT $6 OFFSET $7"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"true", "1", "2", "3", "%go%", 5, 5}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+
+	p, err = NewPaginator(
+		WithTable("posts"),
+		WithStruct(Post{}),
+		WithJSONFiltersKey("where"),
+		WithQueryJSON([]byte(`{"where": {"gte": {"id": 10}}}`)),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	query, _ = p.GenerateSQL()
+	if !strings.Contains(query, "WHERE posts.id >= $1") {
+		t.Errorf("Expected the filter under the custom key, got: %s", query)
+	}
+
+	for _, body := range []string{`{"page": `, `{"filters": ["eq"]}`} {
+		if _, err := NewPaginator(WithTable("posts"), WithStruct(Post{}), WithQueryJSON([]byte(body))); err == nil {
+			t.Errorf("Expected error for body %s", body)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package paginate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Queryer is implemented by *sql.DB, *sql.Tx and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// StreamRows visits every row matching the params without loading them all in
+// memory. Rows are fetched in keyset batches of batchSize ordered by the id
+// column, so any sort and pagination set on the params is ignored. Each row is
+// read with scan, and key returns the id of a scanned row, which is used as
+// the lower bound of the next batch, so the id column must be resolvable and
+// allowed by the field guard, and key must return a non-nil, increasing id. With
+// WithDedupeRows, rows repeating the key of the previous row are skipped.
+func StreamRows[T any](
+	ctx context.Context,
+	db Queryer,
+	params *QueryParams,
+	batchSize int,
+	scan func(rows *sql.Rows) (T, error),
+	key func(row T) interface{},
+	fn func(row T) error,
+) error {
+	if batchSize <= 0 {
+		return errors.New("batch size must be greater than zero")
+	}
+	if params.columnName("id") == "" {
+		return errors.New("id column is required to stream rows")
+	}
+	// The batches are sorted and bounded by the id column, so without either
+	// the first batch would be fetched forever.
+	if params.guardedColumnName("id", "sort") == "" || params.guardedColumnName("id", string(OpGt)) == "" {
+		return errors.New("id column is rejected by the field guard, so rows can't be streamed")
+	}
+
+	// Rows come ordered by the id column, so duplicates are consecutive.
+	var previousKey interface{}
+	skip := func(row T) bool {
+		if !params.DedupeRows {
+			return false
+		}
+		rowKey := key(row)
+		duplicate := previousKey != nil && reflect.DeepEqual(rowKey, previousKey)
+		previousKey = rowKey
+		return duplicate
+	}
+
+	var lastKey interface{}
+	for {
+		batch := *params
+		batch.Page = 1
+		batch.ItemsPerPage = batchSize
+		batch.NoOffset = true
+		batch.Cursor = nil
+		batch.SortColumns = []string{"id"}
+		batch.SortDirections = []string{"false"}
+		batch.Filters = append([]Filter{}, params.Filters...)
+		if lastKey != nil {
+			batch.Filters = append(batch.Filters, Filter{Field: "id", Operator: OpGt, Values: []interface{}{lastKey}})
+		}
+
+		query, args := batch.GenerateSQL()
+		count, last, err := streamBatch(ctx, db, query, args, scan, skip, fn)
+		if err != nil {
+			return err
+		}
+		if count < batchSize {
+			return nil
+		}
+		// The next batch starts after the key, so a key that doesn't move
+		// forward would fetch the same batch forever.
+		nextKey := key(last)
+		if nextKey == nil {
+			return errors.New("key returned nil for the last row of a full batch")
+		}
+		if reflect.DeepEqual(nextKey, lastKey) {
+			return fmt.Errorf("key returned %v for the last row of two batches", nextKey)
+		}
+		lastKey = nextKey
+	}
+}
+
+// streamBatch runs a single batch query, passing each row not skipped to fn,
+// and returns the number of rows read and the last one.
+func streamBatch[T any](
+	ctx context.Context,
+	db Queryer,
+	query string,
+	args []interface{},
+	scan func(rows *sql.Rows) (T, error),
+	skip func(row T) bool,
+	fn func(row T) error,
+) (int, T, error) {
+	var last T
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, last, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		row, err := scan(rows)
+		if err != nil {
+			return count, last, err
+		}
+		if !skip(row) {
+			if err := fn(row); err != nil {
+				return count, last, err
+			}
+		}
+		last = row
+		count++
+	}
+	return count, last, rows.Err()
+}
@@ -0,0 +1,215 @@
+package paginate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// scanUser scans a users row used by the stream tests.
+func scanUser(rows *sql.Rows) (User, error) {
+	var user User
+	err := rows.Scan(&user.ID, &user.Name)
+	return user, err
+}
+
+// TestStreamRows tests visiting all rows across multiple keyset batches.
+func TestStreamRows(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT users.id, users.name FROM users WHERE users.age > $1 ORDER BY users.id ASC LIMIT $2").
+		WithArgs(18, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "john").AddRow(2, "jane"))
+	mock.ExpectQuery("SELECT users.id, users.name FROM users WHERE users.age > $1 AND users.id > $2 ORDER BY users.id ASC LIMIT $3").
+		WithArgs(18, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "mary").AddRow(4, "paul"))
+	mock.ExpectQuery("SELECT users.id, users.name FROM users WHERE users.age > $1 AND users.id > $2 ORDER BY users.id ASC LIMIT $3").
+		WithArgs(18, 4, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "anna"))
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.id"),
+		WithColumn("users.name"),
+		WithFilter("age", OpGt, 18),
+		WithSort([]string{"name"}, []string{"true"}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	err = StreamRows(context.Background(), db, p, 2, scanUser,
+		func(user User) interface{} { return user.ID },
+		func(user User) error {
+			names = append(names, user.Name)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedNames := []string{"john", "jane", "mary", "paul", "anna"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected rows: %v\nGot: %v", expectedNames, names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// TestStreamRowsCallbackError tests that an error from the callback stops the stream.
+func TestStreamRowsCallbackError(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT * FROM users ORDER BY users.id ASC LIMIT $1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "john").AddRow(2, "jane"))
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stop := errors.New("stop")
+	err = StreamRows(context.Background(), db, p, 2, scanUser,
+		func(user User) interface{} { return user.ID },
+		func(user User) error { return stop },
+	)
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected callback error, got: %v", err)
+	}
+}
+
+// TestStreamRowsFieldGuard tests that a field guard rejecting the id column
+// fails the stream instead of fetching the first batch forever.
+func TestStreamRowsFieldGuard(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithFieldGuard(func(field, op string) bool { return field != "id" || op != "sort" }),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	err = StreamRows(context.Background(), db, p, 2, scanUser,
+		func(user User) interface{} { return user.ID },
+		func(user User) error { return nil },
+	)
+	if err == nil {
+		t.Error("Expected error for an id column rejected by the field guard")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// TestStreamRowsDedupe tests skipping the rows duplicated by a join.
+func TestStreamRowsDedupe(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT users.id, users.name FROM users INNER JOIN orders ON orders.user_id = users.id ORDER BY users.id ASC LIMIT $1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "john").AddRow(1, "john").AddRow(2, "jane"))
+	mock.ExpectQuery("SELECT users.id, users.name FROM users INNER JOIN orders ON orders.user_id = users.id WHERE users.id > $1 ORDER BY users.id ASC LIMIT $2").
+		WithArgs(2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "mary").AddRow(3, "mary"))
+
+	p, err := NewPaginator(
+		WithTable("users"),
+		WithStruct(User{}),
+		WithColumn("users.id"),
+		WithColumn("users.name"),
+		WithJoin("INNER JOIN orders ON orders.user_id = users.id"),
+		WithDedupeRows(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	err = StreamRows(context.Background(), db, p, 3, scanUser,
+		func(user User) interface{} { return user.ID },
+		func(user User) error {
+			names = append(names, user.Name)
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedNames := []string{"john", "jane", "mary"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected rows: %v\nGot: %v", expectedNames, names)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+// TestStreamRowsInvalidKey tests stopping when the key can't bound the next batch.
+func TestStreamRowsInvalidKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  func(user User) interface{}
+	}{
+		{"nil", func(user User) interface{} { return nil }},
+		{"constant", func(user User) interface{} { return 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectQuery("SELECT users.id, users.name FROM users ORDER BY users.id ASC LIMIT $1").
+				WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "john"))
+			mock.ExpectQuery("SELECT users.id, users.name FROM users WHERE users.id > $1 ORDER BY users.id ASC LIMIT $2").
+				WithArgs(1, 1).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "jane"))
+
+			p, err := NewPaginator(WithTable("users"), WithStruct(User{}), WithColumn("users.id"), WithColumn("users.name"))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			err = StreamRows(context.Background(), db, p, 1, scanUser, tt.key, func(user User) error { return nil })
+			if err == nil {
+				t.Error("Expected error for a key that doesn't move forward")
+			}
+		})
+	}
+}
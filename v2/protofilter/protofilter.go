@@ -0,0 +1,100 @@
+// Package protofilter maps protobuf messages to paginate filters, keeping the
+// protobuf dependency out of the core paginate package.
+package protofilter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/booscaaa/go-paginate/v2/paginate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FromProtoFields reads the populated fields of the message and maps them to
+// filter options. The fieldMap maps proto field names to filter keys in the
+// `op[field]` convention, e.g. {"min_age": "gte[age]"}. Populated fields
+// missing from the fieldMap are ignored.
+func FromProtoFields(msg proto.Message, fieldMap map[string]string) ([]paginate.Option, error) {
+	var fields []protoreflect.FieldDescriptor
+	values := map[protoreflect.FieldNumber]protoreflect.Value{}
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		fields = append(fields, fd)
+		values[fd.Number()] = v
+		return true
+	})
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Number() < fields[j].Number()
+	})
+
+	var options []paginate.Option
+	for _, fd := range fields {
+		key, ok := fieldMap[string(fd.Name())]
+		if !ok {
+			continue
+		}
+
+		operator, field, err := parseFilterKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		filterValues, err := fieldValues(fd, values[fd.Number()])
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, paginate.WithFilter(field, operator, filterValues...))
+	}
+
+	return options, nil
+}
+
+// fieldValues converts a populated proto field into filter values. Repeated
+// fields produce one value per element.
+func fieldValues(fd protoreflect.FieldDescriptor, v protoreflect.Value) ([]interface{}, error) {
+	if fd.IsMap() {
+		return nil, fmt.Errorf("unsupported map field %q", fd.Name())
+	}
+
+	if fd.IsList() {
+		list := v.List()
+		values := make([]interface{}, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			value, err := scalarValue(fd, list.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	value, err := scalarValue(fd, v)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{value}, nil
+}
+
+// scalarValue converts a singular proto value into a Go value.
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return nil, fmt.Errorf("unsupported message field %q", fd.Name())
+	case protoreflect.EnumKind:
+		return int32(v.Enum()), nil
+	default:
+		return v.Interface(), nil
+	}
+}
+
+// parseFilterKey splits a key in the `op[field]` convention into its operator and field.
+func parseFilterKey(key string) (paginate.Operator, string, error) {
+	open := strings.Index(key, "[")
+	if open <= 0 || !strings.HasSuffix(key, "]") || open+1 == len(key)-1 {
+		return "", "", fmt.Errorf("invalid filter key %q", key)
+	}
+	return paginate.Operator(key[:open]), key[open+1 : len(key)-1], nil
+}
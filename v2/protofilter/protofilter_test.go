@@ -0,0 +1,96 @@
+package protofilter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/booscaaa/go-paginate/v2/paginate"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// User struct used for testing.
+type User struct {
+	ID    int    `json:"id" paginate:"users.id"`
+	Name  string `json:"name" paginate:"users.name"`
+	Age   int    `json:"age" paginate:"users.age"`
+	Role  int    `json:"role" paginate:"users.role"`
+	Email string `json:"email" paginate:"users.email"`
+}
+
+// TestFromProtoFields tests mapping populated proto fields to filters.
+func TestFromProtoFields(t *testing.T) {
+	msg := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("john"),
+		Number: proto.Int32(30),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+	}
+
+	options, err := FromProtoFields(msg, map[string]string{
+		"name":      "eq[name]",
+		"number":    "gte[age]",
+		"label":     "eq[role]",
+		"json_name": "like[email]",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p, err := paginate.NewPaginator(append([]paginate.Option{
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+	}, options...)...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	expectedQuery := "SELECT * FROM users WHERE users.name = $1 AND users.age >= $2 AND users.role = $3 LIMIT $4 OFFSET $5"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	expectedArgs := []interface{}{"john", int32(30), int32(3), 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestFromProtoFieldsRepeated tests mapping a repeated proto field to an IN filter.
+func TestFromProtoFieldsRepeated(t *testing.T) {
+	msg := &descriptorpb.DescriptorProto{
+		ReservedName: []string{"john", "jane"},
+	}
+
+	options, err := FromProtoFields(msg, map[string]string{"reserved_name": "in[name]"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	p, err := paginate.NewPaginator(append([]paginate.Option{
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+	}, options...)...)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args := p.GenerateSQL()
+	if !strings.Contains(query, "WHERE users.name IN ($1, $2)") {
+		t.Errorf("Expected IN filter, got: %s", query)
+	}
+	expectedArgs := []interface{}{"john", "jane", 10, 0}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestFromProtoFieldsInvalidKey tests an invalid filter key in the field map.
+func TestFromProtoFieldsInvalidKey(t *testing.T) {
+	msg := &descriptorpb.FieldDescriptorProto{Name: proto.String("john")}
+
+	_, err := FromProtoFields(msg, map[string]string{"name": "name"})
+	if err == nil || !strings.Contains(err.Error(), `invalid filter key "name"`) {
+		t.Errorf("Expected invalid filter key error, got: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Package sqadapter composes the paginate filters, sort and limit into a
+// Squirrel select builder, keeping the Squirrel dependency out of the core
+// paginate package.
+package sqadapter
+
+import (
+	"github.com/Masterminds/squirrel"
+	"github.com/booscaaa/go-paginate/v2/paginate"
+)
+
+// ToSquirrel translates the paginator into a Squirrel select builder using the
+// placeholder format of its dialect. The pagination is rendered as the core
+// query does, e.g. OFFSET ... FETCH on Oracle and SQL Server or the id subquery
+// of JoinSafePagination, as a suffix of the builder.
+func ToSquirrel(params *paginate.QueryParams) squirrel.SelectBuilder {
+	parts := params.Parts()
+
+	columns := parts.Columns
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+	builder := squirrel.Select(columns...)
+	if len(parts.ColumnArgs) > 0 {
+		builder = squirrel.Select().Column(columns[0], parts.ColumnArgs...)
+	}
+	builder = builder.From(parts.From).PlaceholderFormat(placeholderFormat(params.Dialect))
+
+	for _, join := range parts.Joins {
+		builder = builder.JoinClause(join)
+	}
+	if parts.Where != "" {
+		builder = builder.Where(parts.Where, parts.WhereArgs...)
+	}
+	if len(parts.GroupBy) > 0 {
+		builder = builder.GroupBy(parts.GroupBy...)
+	}
+	if parts.Having != "" {
+		builder = builder.Having(parts.Having, parts.HavingArgs...)
+	}
+	if parts.OrderBy != "" {
+		builder = builder.OrderBy(parts.OrderBy)
+	}
+
+	if parts.Pagination != "" {
+		builder = builder.Suffix(parts.Pagination, parts.PaginationArgs...)
+	}
+	return builder
+}
+
+// placeholderFormat returns the Squirrel placeholder format of the dialect.
+func placeholderFormat(dialect paginate.Dialect) squirrel.PlaceholderFormat {
+	switch dialect {
+	case paginate.DialectMySQL, paginate.DialectSQLite:
+		return squirrel.Question
+	case paginate.DialectOracle:
+		return squirrel.Colon
+	case paginate.DialectSQLServer:
+		return squirrel.AtP
+	default:
+		return squirrel.Dollar
+	}
+}
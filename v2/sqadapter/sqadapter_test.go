@@ -0,0 +1,179 @@
+package sqadapter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/booscaaa/go-paginate/v2/paginate"
+)
+
+// User struct used for testing.
+type User struct {
+	ID    int    `json:"id" paginate:"users.id"`
+	Name  string `json:"name" paginate:"users.name"`
+	Email string `json:"email" paginate:"users.email"`
+	Age   int    `json:"age" paginate:"users.age"`
+}
+
+// TestToSquirrel tests that the Squirrel-rendered SQL matches the core-generated SQL.
+func TestToSquirrel(t *testing.T) {
+	params, err := paginate.NewPaginator(
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+		paginate.WithColumn("users.id"),
+		paginate.WithColumn("users.name"),
+		paginate.WithJoin("INNER JOIN posts ON posts.user_id = users.id"),
+		paginate.WithSearch("john"),
+		paginate.WithSearchFields([]string{"name", "email"}),
+		paginate.WithFilter("age", paginate.OpGte, 18),
+		paginate.WithFilter("id", paginate.OpIn, []int{1, 2, 3}),
+		paginate.WithSort([]string{"name"}, []string{"true"}),
+		paginate.WithPage(3),
+		paginate.WithItemsPerPage(20),
+		paginate.WithInlineLimitOffset(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery, expectedArgs := params.GenerateSQL()
+	query, args, err := ToSquirrel(params).ToSql()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestToSquirrelGroupBy tests translating GROUP BY and HAVING without an offset.
+func TestToSquirrelGroupBy(t *testing.T) {
+	params, err := paginate.NewPaginator(
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+		paginate.WithColumn("users.age"),
+		paginate.WithColumn("COUNT(*)"),
+		paginate.WithGroupBy("users.age"),
+		paginate.WithHaving("COUNT(*) > ?", 5),
+		paginate.WithNoOffset(true),
+		paginate.WithInlineLimitOffset(),
+		paginate.WithDialect(paginate.DialectMySQL),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	query, args, err := ToSquirrel(params).ToSql()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectedQuery := "SELECT users.age, COUNT(*) FROM users GROUP BY users.age HAVING COUNT(*) > ? LIMIT 10"
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{5}) {
+		t.Errorf("Expected args: [5]\nGot: %v", args)
+	}
+}
+
+// TestToSquirrelRawSelect tests translating a raw select with its arguments first.
+func TestToSquirrelRawSelect(t *testing.T) {
+	params, err := paginate.NewPaginator(
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+		paginate.WithRawSelect("users.id, CASE WHEN users.age >= ? THEN 'adult' ELSE 'minor' END AS bracket", 18),
+		paginate.WithFilter("name", paginate.OpEq, "john"),
+		paginate.WithInlineLimitOffset(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery, expectedArgs := params.GenerateSQL()
+	query, args, err := ToSquirrel(params).ToSql()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestToSquirrelSearchRank tests translating the search rank column the sort refers to.
+func TestToSquirrelSearchRank(t *testing.T) {
+	params, err := paginate.NewPaginator(
+		paginate.WithTable("users"),
+		paginate.WithStruct(User{}),
+		paginate.WithSearch("john"),
+		paginate.WithSearchFields([]string{"name"}),
+		paginate.WithSearchRank("score"),
+		paginate.WithInlineLimitOffset(),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedQuery, expectedArgs := params.GenerateSQL()
+	query, args, err := ToSquirrel(params).ToSql()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query != expectedQuery {
+		t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+	}
+	if !reflect.DeepEqual(args, expectedArgs) {
+		t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+	}
+}
+
+// TestToSquirrelDialectPagination tests translating the pagination of each
+// dialect and of join-safe pagination like the core query.
+func TestToSquirrelDialectPagination(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []paginate.Option
+	}{
+		{"oracle", []paginate.Option{paginate.WithDialect(paginate.DialectOracle)}},
+		{"sqlserver", []paginate.Option{paginate.WithDialect(paginate.DialectSQLServer)}},
+		{"mysql", []paginate.Option{paginate.WithDialect(paginate.DialectMySQL), paginate.WithInlineLimitOffset()}},
+		{"ansi", []paginate.Option{paginate.WithAnsiLimit()}},
+		{"join safe", []paginate.Option{
+			paginate.WithJoin("INNER JOIN posts ON posts.user_id = users.id"),
+			paginate.WithJoinSafePagination(),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]paginate.Option{
+				paginate.WithTable("users"),
+				paginate.WithStruct(User{}),
+				paginate.WithFilter("age", paginate.OpGte, 18),
+				paginate.WithSort([]string{"name"}, []string{"false"}),
+				paginate.WithPage(2),
+			}, tt.options...)
+			params, err := paginate.NewPaginator(options...)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expectedQuery, expectedArgs := params.GenerateSQL()
+			query, args, err := ToSquirrel(params).ToSql()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if query != expectedQuery {
+				t.Errorf("Expected query:\n%s\nGot:\n%s", expectedQuery, query)
+			}
+			if !reflect.DeepEqual(args, expectedArgs) {
+				t.Errorf("Expected args: %v\nGot: %v", expectedArgs, args)
+			}
+		})
+	}
+}